@@ -34,7 +34,13 @@ type BuildInfo struct {
 // - Drone.io: http://docs.drone.io/environment-reference/
 // - GitLab CI: https://docs.gitlab.com/ee/ci/variables/#predefined-variables-environment-variables
 // - GitLab CI doesn't export ID of Merge Request. https://gitlab.com/gitlab-org/gitlab-ce/issues/15280
+// - Gitea Actions: https://docs.gitea.com/usage/actions/comparison#context-availability
+// - Forgejo Actions: https://forgejo.org/docs/latest/user/actions/#environment-variables
 func GetBuildInfo() (prInfo *BuildInfo, isPR bool, err error) {
+	// Check Gitea Actions first as it also sets GITHUB_ACTIONS.
+	if IsInGiteaActions() {
+		return getBuildInfoFromGiteaActions()
+	}
 	if IsInGitHubAction() {
 		return getBuildInfoFromGitHubAction()
 	}
@@ -0,0 +1,66 @@
+package cienv
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// IsInGiteaActions returns true if reviewdog is running in Gitea Actions or
+// Forgejo Actions.
+//
+// https://docs.gitea.com/usage/actions/comparison#context-availability
+// https://forgejo.org/docs/latest/user/actions/#environment-variables
+func IsInGiteaActions() bool {
+	return os.Getenv("GITEA_ACTIONS") == "true" || os.Getenv("FORGEJO_ACTIONS") == "true"
+}
+
+// giteaActionsEnv returns the value of FORGEJO_<name> or GITHUB_<name>
+// environment variable. Gitea Actions and Forgejo Actions export GitHub
+// compatible variables, and Forgejo also exports FORGEJO_* variables.
+func giteaActionsEnv(name string) string {
+	return getOneEnvValue([]string{"FORGEJO_" + name, "GITHUB_" + name})
+}
+
+// GiteaActionsServerURL returns the base URL of the Gitea (or Forgejo) server
+// running the current workflow. It returns empty string if it's not running
+// in Gitea Actions.
+func GiteaActionsServerURL() string {
+	if !IsInGiteaActions() {
+		return ""
+	}
+	return giteaActionsEnv("SERVER_URL")
+}
+
+// GiteaActionsRunURL returns the URL of the current Gitea Actions workflow
+// run. It returns empty string if it's not running in Gitea Actions.
+func GiteaActionsRunURL() string {
+	server, repo, runNumber := GiteaActionsServerURL(), giteaActionsEnv("REPOSITORY"), giteaActionsEnv("RUN_NUMBER")
+	if server == "" || repo == "" || runNumber == "" {
+		return ""
+	}
+	return strings.TrimSuffix(server, "/") + "/" + repo + "/actions/runs/" + runNumber
+}
+
+func getBuildInfoFromGiteaActions() (*BuildInfo, bool, error) {
+	eventPath := giteaActionsEnv("EVENT_PATH")
+	if eventPath == "" {
+		return nil, false, errors.New("GITHUB_EVENT_PATH not found")
+	}
+	// The event payload of Gitea webhooks is compatible with GitHub one for
+	// fields reviewdog uses.
+	info, isPR, err := getBuildInfoFromGitHubActionEventPath(eventPath)
+	if err != nil {
+		return nil, false, err
+	}
+	if info.Owner == "" || info.Repo == "" {
+		info.Owner, info.Repo = getOwnerAndRepoFromSlug([]string{"FORGEJO_REPOSITORY", "GITHUB_REPOSITORY"})
+	}
+	if info.SHA == "" {
+		info.SHA = giteaActionsEnv("SHA")
+	}
+	if info.Branch == "" {
+		info.Branch = getOneEnvValue([]string{"FORGEJO_HEAD_REF", "GITHUB_HEAD_REF", "FORGEJO_REF_NAME", "GITHUB_REF_NAME"})
+	}
+	return info, isPR, nil
+}
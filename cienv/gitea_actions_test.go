@@ -0,0 +1,61 @@
+package cienv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetBuildInfo_giteaActions(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITEA_ACTIONS", "true")
+	t.Setenv("GITHUB_EVENT_PATH", "_testdata/gitea_event_pull_request.json")
+
+	got, isPR, err := GetBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isPR {
+		t.Error("should be pull request build")
+	}
+	want := &BuildInfo{Owner: "reviewdog", Repo: "reviewdog", SHA: "4d3e0a9f7c0e0b1f2c9d8e7a6b5c4d3e2f1a0b9c", PullRequest: 12, Branch: "add-reviewdog"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
+
+func TestGetBuildInfo_forgejoActionsPush(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITEA_ACTIONS", "")
+	t.Setenv("FORGEJO_ACTIONS", "true")
+	t.Setenv("FORGEJO_EVENT_PATH", "_testdata/github_event_push.json")
+	t.Setenv("FORGEJO_REF_NAME", "main")
+
+	got, isPR, err := GetBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isPR {
+		t.Error("should be non pull-request build")
+	}
+	want := &BuildInfo{Owner: "reviewdog", Repo: "reviewdog", SHA: "febdd4bf26c6e8856c792303cfc66fa5e7bc975b", Branch: "main"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
+
+func TestGiteaActionsRunURL(t *testing.T) {
+	t.Setenv("GITEA_ACTIONS", "true")
+	t.Setenv("FORGEJO_ACTIONS", "")
+	t.Setenv("GITHUB_SERVER_URL", "https://gitea.example.com/")
+	t.Setenv("GITHUB_REPOSITORY", "reviewdog/reviewdog")
+	t.Setenv("GITHUB_RUN_NUMBER", "14")
+	if got, want := GiteaActionsRunURL(), "https://gitea.example.com/reviewdog/reviewdog/actions/runs/14"; got != want {
+		t.Errorf("GiteaActionsRunURL() = %q, want %q", got, want)
+	}
+
+	t.Setenv("GITEA_ACTIONS", "")
+	if got := GiteaActionsRunURL(); got != "" {
+		t.Errorf("GiteaActionsRunURL() = %q, want empty outside Gitea Actions", got)
+	}
+}
@@ -120,3 +120,14 @@ func HasReadOnlyPermissionGitHubToken() bool {
 	isForkedRepo := event.PullRequest.Head.Repo.Owner.ID != event.PullRequest.Base.Repo.Owner.ID
 	return isForkedRepo && event.ActionName != "pull_request_target"
 }
+
+// GitHubActionsRunURL returns the URL of the current GitHub Actions workflow
+// run. It returns empty string if it's not running in GitHub Actions.
+// https://docs.github.com/en/actions/learn-github-actions/variables#default-environment-variables
+func GitHubActionsRunURL() string {
+	server, repo, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if !IsInGitHubAction() || server == "" || repo == "" || runID == "" {
+		return ""
+	}
+	return server + "/" + repo + "/actions/runs/" + runID
+}
@@ -38,3 +38,18 @@ func TestGetBuildInfoFromGitHubActionEventPath_pushevent(t *testing.T) {
 		t.Errorf("result has diff:\n%s", diff)
 	}
 }
+
+func TestGitHubActionsRunURL(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "reviewdog/reviewdog")
+	t.Setenv("GITHUB_RUN_ID", "1414")
+	if got, want := GitHubActionsRunURL(), "https://github.com/reviewdog/reviewdog/actions/runs/1414"; got != want {
+		t.Errorf("GitHubActionsRunURL() = %q, want %q", got, want)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	if got := GitHubActionsRunURL(); got != "" {
+		t.Errorf("GitHubActionsRunURL() = %q, want empty outside GitHub Actions", got)
+	}
+}
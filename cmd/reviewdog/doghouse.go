@@ -22,7 +22,7 @@ import (
 	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
-func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isProject bool) error {
+func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isProject bool, at *apiTransport) error {
 	ghInfo, _, err := cienv.GetBuildInfo()
 	if err != nil {
 		return err
@@ -31,7 +31,7 @@ func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isP
 	if err != nil {
 		return err
 	}
-	cli := newDoghouseCli(ctx)
+	cli := newDoghouseCli(ctx, at)
 	if cli == nil {
 		return errors.New("failed to create a doghouse client")
 	}
@@ -49,12 +49,13 @@ func skipDoghouseServer() bool {
 	return (os.Getenv("REVIEWDOG_SKIP_DOGHOUSE") == "true" || cienv.IsInGitHubAction()) && os.Getenv("REVIEWDOG_TOKEN") == ""
 }
 
-func newDoghouseCli(ctx context.Context) *client.DogHouseClient {
-	httpCli := http.DefaultClient
+func newDoghouseCli(ctx context.Context, at *apiTransport) *client.DogHouseClient {
+	httpCli := &http.Client{Transport: at.wrap(http.DefaultTransport)}
 	if token := os.Getenv("REVIEWDOG_TOKEN"); token != "" {
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpCli)
 		httpCli = oauth2.NewClient(ctx, ts)
 	}
 	return client.New(httpCli)
@@ -133,6 +134,9 @@ func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
 				log.Printf("[%s] reported: %s%s", name, res.ReportURL, conclusion)
 			}
 			if res.ReportURL == "" {
+				if opt.dryRun {
+					return nil
+				}
 				return fmt.Errorf("[%s] no result found", name)
 			}
 			// If failOnError is on, return error when at least one report
@@ -22,6 +22,7 @@ import (
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/project"
 	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 func TestDiagnosticResultSet_Project(t *testing.T) {
@@ -302,3 +303,39 @@ func absPath(t *testing.T, path string) string {
 	}
 	return p
 }
+
+func TestPostResultSet_dryRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+	}))
+	defer ts.Close()
+
+	var out strings.Builder
+	cli := client.New(&http.Client{Transport: serviceutil.NewDryRunTransport(nil, &out)})
+	cli.BaseURL, _ = url.Parse(ts.URL)
+
+	var resultSet reviewdog.ResultMap
+	resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+		{Location: &rdf.Location{Path: "reviewdog.go"}, Message: "test"},
+	}})
+	ghInfo := &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", PullRequest: 14, SHA: "1414"}
+
+	opt := &option{filterMode: filter.ModeAdded, dryRun: true}
+	if err := postResultSet(context.Background(), &resultSet, ghInfo, cli, opt); err != nil {
+		t.Fatal(err)
+	}
+	var got serviceutil.DryRunRequest
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("failed to decode dry-run output %q: %v", out.String(), err)
+	}
+	if want := ts.URL + "/check"; got.Method != http.MethodPost || got.URL != want {
+		t.Errorf("dry-run request = %s %s, want POST %s", got.Method, got.URL, want)
+	}
+	var req doghouse.CheckRequest
+	if err := json.Unmarshal(got.Body, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "name1" || len(req.Annotations) != 1 {
+		t.Errorf("unexpected check request: %+v", req)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 
 	"code.gitea.io/sdk/gitea"
 	"golang.org/x/build/gerrit"
@@ -33,11 +34,13 @@ import (
 	"github.com/reviewdog/reviewdog/parser"
 	"github.com/reviewdog/reviewdog/project"
 	bbservice "github.com/reviewdog/reviewdog/service/bitbucket"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 	gerritservice "github.com/reviewdog/reviewdog/service/gerrit"
 	giteaservice "github.com/reviewdog/reviewdog/service/gitea"
 	githubservice "github.com/reviewdog/reviewdog/service/github"
 	"github.com/reviewdog/reviewdog/service/github/githubutils"
 	gitlabservice "github.com/reviewdog/reviewdog/service/gitlab"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 const usageMessage = "" +
@@ -65,13 +68,17 @@ type option struct {
 	failOnError      bool
 	failLevel        reviewdog.FailLevel
 	logLevel         string
+	dryRun           bool
+	httpRecord       string
+	httpReplay       string
+	commentTemplate  string
 }
 
 const (
 	diffCmdDoc    = `diff command (e.g. "git diff") for local reporters. Do not use --relative flag for git command.`
 	diffStripDoc  = "strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)"
 	efmsDoc       = `list of supported machine-readable format and errorformat (https://github.com/reviewdog/errorformat)`
-	fDoc          = `format name (run -list to see supported format name) for input. It's also used as tool name in review comment if -name is empty`
+	fDoc          = `format name (run -list to see supported format name) for input. "auto" detects the format from the input and requires -name. It's also used as tool name in review comment if -name is empty`
 	fDiffStripDoc = `option for -f=diff: strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)`
 	listDoc       = `list supported pre-defined format names which can be used as -f arg`
 	nameDoc       = `tool name in review comment. -f is used as tool name if -name is empty`
@@ -129,6 +136,18 @@ const (
 	"github-pr-check"
 		Same as github-check reporter but it only supports Pull Requests.
 
+	"github-status"
+		Report results to GitHub commit status. It sets a commit status per
+		tool (context is tool name) and the state is "failure" if it finds at
+		least 1 issue with severity greater than or equal to -fail-level (any
+		issue if -fail-level is not set), otherwise "success".
+		It's useful when reviewdog cannot use GitHub Check API (e.g. Pull
+		Requests from forked repository) or to require a status per tool.
+
+		1. Set REVIEWDOG_GITHUB_API_TOKEN environment variable.
+		2. Optionally set REVIEWDOG_STATUS_TARGET_URL to link the status to a
+		report URL. It links to the workflow run in GitHub Actions by default.
+
 	"github-pr-review"
 		Report results to GitHub review comments.
 
@@ -154,10 +173,23 @@ const (
 		Alternatively, GITLAB_API can also be defined, and it will take precedence over the former:
 			$ export GITLAB_API="https://example.gitlab.com/api/v4"
 
+		Set GITLAB_DRAFT_NOTES=true to create draft notes for new findings and
+		publish them at once, so that reviewers get a single notification.
+		Other pending draft notes of the token user are published together.
+
 	"gitlab-mr-commit"
 		Same as gitlab-mr-discussion, but report results to GitLab comments for
 		each commits in Merge Requests.
 
+	"gitlab-commit-status"
+		Report results to GitLab commit status. It sets a commit status per
+		tool (name is tool name) and the state is "failed" if it finds at least
+		1 issue with severity greater than or equal to -fail-level (any issue if
+		-fail-level is not set), otherwise "success".
+		It requires REVIEWDOG_GITLAB_API_TOKEN same as gitlab-mr-discussion.
+		The status links to CI_JOB_URL (defined by GitLab CI) or
+		REVIEWDOG_STATUS_TARGET_URL.
+
 	"gerrit-change-review"
 		Report results to Gerrit Change comments.
 
@@ -171,6 +203,20 @@ const (
 			$ export GERRIT_BRANCH=master
 			$ export GERRIT_ADDRESS=http://localhost:8080
 
+		Set GERRIT_ROBOT_COMMENTS=true to post robot comments with fix
+		suggestions built from diagnostic suggestions instead of plain comments.
+		GERRIT_ROBOT_RUN_ID is used as robot_run_id (default: GERRIT_REVISION_ID).
+
+		Set GERRIT_LABEL (e.g. Verified) to vote on the label with a summary
+		message. It votes -1 if it finds at least 1 issue with severity greater
+		than or equal to -fail-level (any issue if -fail-level is not set),
+		otherwise +1.
+
+		Set GERRIT_API_DIFF=true to get the diff with Gerrit REST API instead of
+		local git command (e.g. in shallow checkouts). Set GERRIT_DIFF_BASE to a
+		patch set number (e.g. 1) to report only findings in lines changed since
+		the patch set. GERRIT_DIFF_BASE implies GERRIT_API_DIFF=true.
+
 	"bitbucket-code-report"
 		Create Bitbucket Code Report via Code Insights
 		(https://confluence.atlassian.com/display/BITBUCKET/Code+insights).
@@ -193,6 +239,21 @@ const (
 		2. Set GITEA_ADDRESS environment variable to your Gitea server base address.
 		For example:
 			$ export GITEA_ADDRESS=http://localhost:3000
+		The review is submitted as REQUEST_CHANGES if it has at least 1 comment
+		with severity greater than or equal to -fail-level.
+		Conversations of comments whose findings are no longer reported are
+		resolved.
+		In Gitea Actions and Forgejo Actions, GITEA_ADDRESS defaults to the
+		server URL and build info is read from the workflow environment.
+
+	"gitea-status"
+		Report results to Gitea commit status. It sets a commit status per
+		tool (context is tool name) and the state is "failure" if it finds at
+		least 1 issue with severity greater than or equal to -fail-level (any
+		issue if -fail-level is not set), otherwise "success".
+		It requires REVIEWDOG_GITEA_API_TOKEN and GITEA_ADDRESS same as
+		gitea-pr-review. The status links to the Gitea Actions run or
+		REVIEWDOG_STATUS_TARGET_URL.
 
 	For GitHub Enterprise and self hosted GitLab or Gitea, set
 	REVIEWDOG_INSECURE_SKIP_VERIFY to skip verifying SSL (please use this at your own risk)
@@ -208,9 +269,13 @@ const (
 		$ export CI_REPO_OWNER="haya14busa" # repository owner
 		$ export CI_REPO_NAME="reviewdog" # repository name
 `
-	failOnErrorDoc = `[DEPRECATED] use -fail-level instead`
-	failLevelDoc   = `reviewdog will exit with code 1 if it finds at least 1 issue with severity greater than or equal to the given level. [none(default),any,info,warning,error]`
-	logLevelDoc    = `log level for reviewdog itself. (debug, info, warning, error)`
+	failOnErrorDoc     = `[DEPRECATED] use -fail-level instead`
+	failLevelDoc       = `reviewdog will exit with code 1 if it finds at least 1 issue with severity greater than or equal to the given level. [none(default),any,info,warning,error]`
+	logLevelDoc        = `log level for reviewdog itself. (debug, info, warning, error)`
+	dryRunDoc          = `print requests which reporters would send to APIs (e.g. review comments and check run annotations) to stdout as JSON Lines instead of sending them. Read-only requests (e.g. fetching diff and existing comments) are still sent. Results are printed to stderr instead of stdout with API reporters.`
+	httpRecordDoc      = `record HTTP requests and responses of API reporters to JSON fixture files in the given directory. Request headers (e.g. credentials) are not recorded.`
+	httpReplayDoc      = `replay HTTP responses recorded with -http-record in the given directory instead of accessing APIs`
+	commentTemplateDoc = `Go text/template of review comment body for comment reporters (e.g. "{{.SeverityIcon}} [{{.ToolName}}] {{.Message}}"). See README for available fields. It can be set per runner with "comment_template" in reviewdog config.`
 )
 
 var opt = &option{}
@@ -234,6 +299,10 @@ func init() {
 	flag.BoolVar(&opt.failOnError, "fail-on-error", false, failOnErrorDoc)
 	flag.Var(&opt.failLevel, "fail-level", failLevelDoc)
 	flag.StringVar(&opt.logLevel, "log-level", "info", logLevelDoc)
+	flag.BoolVar(&opt.dryRun, "dry-run", false, dryRunDoc)
+	flag.StringVar(&opt.httpRecord, "http-record", "", httpRecordDoc)
+	flag.StringVar(&opt.httpReplay, "http-replay", "", httpReplayDoc)
+	flag.StringVar(&opt.commentTemplate, "comment-template", "", commentTemplateDoc)
 }
 
 func usage() {
@@ -291,10 +360,33 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		return runList(w)
 	}
 
+	if opt.f == "auto" && opt.name == "" {
+		// The tool name identifies checks, statuses and comments of previous
+		// runs, so it must not vary with the detected format.
+		return errors.New("-name is required with -f=auto")
+	}
+
 	if opt.tee {
 		r = io.TeeReader(r, w)
 	}
 
+	at, err := newAPITransport(w, opt)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := commentTemplate(opt)
+	if err != nil {
+		return err
+	}
+	resultWriter := w
+	if opt.dryRun {
+		if opt.reporter != "local" {
+			// Keep stdout for request payloads.
+			resultWriter = os.Stderr
+		}
+	}
+
 	// assume it's project based run when both -efm and -f are not specified
 	isProject := len(opt.efms) == 0 && opt.f == ""
 	var projectConf *project.Config
@@ -308,10 +400,16 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		if err != nil {
 			return err
 		}
+		// -comment-template is the default of runners without comment_template.
+		for _, runner := range projectConf.Runner {
+			if runner.CommentTemplate == "" {
+				runner.CommentTemplate = opt.commentTemplate
+			}
+		}
 
-		cs = reviewdog.NewUnifiedCommentWriter(w)
+		cs = reviewdog.NewUnifiedCommentWriter(resultWriter)
 	} else {
-		cs = reviewdog.NewRawCommentWriter(w)
+		cs = reviewdog.NewRawCommentWriter(resultWriter)
 	}
 
 	switch opt.reporter {
@@ -319,11 +417,11 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		return fmt.Errorf("unknown -reporter: %s", opt.reporter)
 	case "github-check", "github-pr-check":
 		if !skipDoghouseServer() {
-			return runDoghouse(ctx, r, w, opt, isProject)
+			return runDoghouse(ctx, r, w, opt, isProject, at)
 		}
 		var err error
 		var isPR bool
-		checkService, ghDiffService, isPR, err := githubCheckService(ctx, opt)
+		checkService, ghDiffService, isPR, err := githubCheckService(ctx, opt, at)
 		if err != nil {
 			return err
 		}
@@ -335,15 +433,25 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 	case "github-annotations", "github-pr-annotations":
 		var err error
 		var isPR bool
-		cs, ds, isPR, err = githubActionLogService(ctx, opt)
+		cs, ds, isPR, err = githubActionLogService(ctx, opt, at)
+		if err != nil {
+			return err
+		}
+		if !isPR {
+			opt.filterMode = filter.ModeNoFilter
+		}
+	case "github-status":
+		ss, ghDiffService, isPR, err := githubStatusService(ctx, opt, at)
 		if err != nil {
 			return err
 		}
 		if !isPR {
 			opt.filterMode = filter.ModeNoFilter
 		}
+		ds = ghDiffService
+		cs = reviewdog.MultiCommentService(ss, cs)
 	case "github-pr-review":
-		gs, isPR, err := githubService(ctx, opt)
+		gs, isPR, err := githubService(ctx, opt, at)
 		if err != nil {
 			return err
 		}
@@ -354,7 +462,7 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		cs = reviewdog.MultiCommentService(gs, cs)
 		ds = gs
 	case "gitlab-mr-discussion":
-		build, cli, err := gitlabBuildWithClient()
+		build, cli, err := gitlabBuildWithClient(at)
 		if err != nil {
 			return err
 		}
@@ -364,10 +472,13 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		}
 
 		gc := gitlabservice.NewGitLabMergeRequestDiscussionCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		if os.Getenv("GITLAB_DRAFT_NOTES") == "true" {
+			gc.EnableDraftNotes()
+		}
 		cs = reviewdog.MultiCommentService(gc, cs)
 		ds = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
 	case "gitlab-mr-commit":
-		build, cli, err := gitlabBuildWithClient()
+		build, cli, err := gitlabBuildWithClient(at)
 		if err != nil {
 			return err
 		}
@@ -377,21 +488,52 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		}
 
 		gc := gitlabservice.NewGitLabMergeRequestCommitCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		gc.SetTool(toolName(opt), opt.level)
 		cs = reviewdog.MultiCommentService(gc, cs)
 		ds = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
-	case "gerrit-change-review":
-		b, cli, err := gerritBuildWithClient()
+	case "gitlab-commit-status":
+		build, cli, err := gitlabBuildWithClient(at)
 		if err != nil {
 			return err
 		}
-		cs = gerritservice.NewChangeReviewCommenter(cli, b.GerritChangeID, b.GerritRevisionID)
-		d, err := gerritservice.NewChangeDiff(cli, b.Branch, b.GerritChangeID)
+		gc := gitlabservice.NewGitLabCommitStatus(cli, build.Owner, build.Repo, build.SHA, toolName(opt), statusTargetURL(), failLevel(opt))
+		cs = reviewdog.MultiCommentService(gc, cs)
+		if build.PullRequest == 0 {
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		} else {
+			ds = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		}
+	case "gerrit-change-review":
+		b, cli, rest, err := gerritBuildWithClient(at)
 		if err != nil {
 			return err
 		}
-		ds = d
+		gc := gerritservice.NewChangeReviewCommenter(cli, b.GerritChangeID, b.GerritRevisionID)
+		gc.SetRESTClient(rest)
+		gc.SetTool(toolName(opt), opt.level)
+		if label := os.Getenv("GERRIT_LABEL"); label != "" {
+			gc.EnableLabelVote(label, failLevel(opt))
+		}
+		if os.Getenv("GERRIT_ROBOT_COMMENTS") == "true" {
+			runID := os.Getenv("GERRIT_ROBOT_RUN_ID")
+			if runID == "" {
+				runID = b.GerritRevisionID
+			}
+			gc.EnableRobotComments(runID)
+		}
+		cs = gc
+		if base := os.Getenv("GERRIT_DIFF_BASE"); base != "" || os.Getenv("GERRIT_API_DIFF") == "true" {
+			ds = gerritservice.NewChangeAPIDiff(rest, b.GerritChangeID, b.GerritRevisionID, base)
+		} else {
+			d, err := gerritservice.NewChangeDiff(cli, b.Branch, b.GerritChangeID)
+			if err != nil {
+				return err
+			}
+			ds = d
+		}
 	case "bitbucket-code-report":
-		build, client, ct, err := bitbucketBuildWithClient(ctx)
+		build, client, ct, err := bitbucketBuildWithClient(ctx, at)
 		if err != nil {
 			return err
 		}
@@ -413,7 +555,7 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		opt.filterMode = filter.ModeNoFilter
 		ds = &reviewdog.EmptyDiff{}
 	case "gitea-pr-review":
-		gs, isPR, err := giteaService(ctx, opt)
+		gs, isPR, err := giteaService(ctx, opt, at)
 		if err != nil {
 			return err
 		}
@@ -423,6 +565,32 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		}
 		cs = reviewdog.MultiCommentService(gs, cs)
 		ds = gs
+	case "gitea-status":
+		g, isPR, client, err := giteaBuildInfoWithClient(ctx, at)
+		if err != nil {
+			return err
+		}
+		// The status reporter sets the context of its client, so it doesn't
+		// share the client with the diff service.
+		statusClient, err := giteaClientFromEnv(ctx, at)
+		if err != nil {
+			return err
+		}
+		gs := giteaservice.NewGiteaStatus(statusClient, g.Owner, g.Repo, g.SHA, toolName(opt), statusTargetURL(), failLevel(opt))
+		cs = reviewdog.MultiCommentService(gs, cs)
+		if isPR {
+			ds = &giteaservice.PullRequestDiffService{
+				Cli:              client,
+				Owner:            g.Owner,
+				Repo:             g.Repo,
+				PR:               int64(g.PullRequest),
+				SHA:              g.SHA,
+				FallBackToGitCLI: true,
+			}
+		} else {
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
 	case "local":
 		d, err := localDiffService(opt)
 		if err != nil {
@@ -452,17 +620,26 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		cs = reviewdog.NewSARIFCommentWriter(w, toolName(opt))
 	}
 
+	var runErr error
 	if isProject {
-		return project.Run(ctx, projectConf, buildRunnersMap(opt.runners), cs, ds, opt.tee, opt.filterMode, failLevel(opt))
-	}
-
-	p, err := newParserFromOpt(opt)
-	if err != nil {
-		return err
+		runErr = project.Run(ctx, projectConf, buildRunnersMap(opt.runners), cs, ds, opt.tee, opt.filterMode, failLevel(opt))
+	} else {
+		p, err := newParserFromOpt(opt)
+		if err != nil {
+			return err
+		}
+		app := reviewdog.NewReviewdog(toolName(opt), p, cs, ds, opt.filterMode, failLevel(opt))
+		app.SetCommentTemplate(tmpl)
+		runErr = app.Run(ctx, r)
+	}
+	// Finish comment services even if a tool fails, so that results of all
+	// tools are reported (e.g. Gerrit label vote).
+	if fcs, ok := cs.(reviewdog.FinishCommentService); ok {
+		if err := fcs.Finish(ctx); err != nil {
+			return errors.Join(runErr, err)
+		}
 	}
-
-	app := reviewdog.NewReviewdog(toolName(opt), p, cs, ds, opt.filterMode, failLevel(opt))
-	return app.Run(ctx, r)
+	return runErr
 }
 
 func runList(w io.Writer) error {
@@ -472,6 +649,12 @@ func runList(w io.Writer) error {
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "diff", "Unified Diff Format", "https://en.wikipedia.org/wiki/Diff#Unified_format")
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "checkstyle", "checkstyle XML format", "http://checkstyle.sourceforge.net/")
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "sarif", "SARIF JSON format", "https://sarifweb.azurewebsites.net/")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "clang-sarif", "Clang SARIF format (-fdiagnostics-format=sarif)", "https://clang.llvm.org/docs/UsersManual.html#cmdoption-fdiagnostics-format")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "gcc-json", "GCC JSON format (-fdiagnostics-format=json)", "https://gcc.gnu.org/onlinedocs/gcc/Diagnostic-Message-Formatting-Options.html")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "cargo-json", "cargo/rustc JSON message format (--message-format=json)", "https://doc.rust-lang.org/cargo/reference/external-tools.html#json-messages")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "govet-json", "go vet -json and go/analysis JSON format", "https://pkg.go.dev/golang.org/x/tools/go/analysis")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "eslint-json", "ESLint JSON format (-f json or -f json-with-metadata)", "https://eslint.org/docs/latest/use/formatters/#json")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "auto", "detect the input format automatically", "https://github.com/reviewdog/reviewdog#auto-format-detection")
 	for _, f := range sortedFmts(fmts.DefinedFmts()) {
 		fmt.Fprintf(tabw, "%s\t%s\t- %s\n", f.Name, f.Description, f.URL)
 	}
@@ -513,35 +696,113 @@ func diffService(s string, strip int) (reviewdog.DiffService, error) {
 	return d, nil
 }
 
-func newHTTPClient() *http.Client {
+// apiTransport wraps HTTP transports of API clients to support -dry-run,
+// -http-record and -http-replay.
+type apiTransport struct {
+	// dryRunWriter is set with -dry-run. Mutating API requests are written to
+	// it instead of being sent.
+	dryRunWriter io.Writer
+	// recorder is set with -http-record.
+	recorder *serviceutil.HTTPRecorder
+	// replayer is set with -http-replay.
+	replayer *serviceutil.HTTPReplayer
+}
+
+func newAPITransport(w io.Writer, opt *option) (*apiTransport, error) {
+	at := &apiTransport{}
+	if opt.dryRun {
+		at.dryRunWriter = w
+	}
+	if opt.httpRecord != "" && opt.httpReplay != "" {
+		return nil, errors.New("-http-record and -http-replay cannot be used together")
+	}
+	var err error
+	if opt.httpRecord != "" {
+		if at.recorder, err = serviceutil.NewHTTPRecorder(opt.httpRecord); err != nil {
+			return nil, err
+		}
+	}
+	if opt.httpReplay != "" {
+		if at.replayer, err = serviceutil.NewHTTPReplayer(opt.httpReplay); err != nil {
+			return nil, err
+		}
+	}
+	return at, nil
+}
+
+// newHTTPClient returns a new HTTP client for API clients.
+func (at *apiTransport) newHTTPClient() *http.Client {
+	return at.newHTTPClientWithDryRunResponse("")
+}
+
+// newHTTPClientWithDryRunResponse returns a new HTTP client for API clients
+// whose mutating requests get the given fake response body in dry-run mode.
+func (at *apiTransport) newHTTPClientWithDryRunResponse(dryRunResponse string) *http.Client {
 	tr := &http.Transport{
 		Proxy:           http.ProxyFromEnvironment,
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify()},
 	}
-	return &http.Client{Transport: tr}
+	return &http.Client{Transport: at.wrapWithDryRunResponse(tr, dryRunResponse)}
+}
+
+// wrap wraps the HTTP transport of an API client.
+func (at *apiTransport) wrap(tr http.RoundTripper) http.RoundTripper {
+	return at.wrapWithDryRunResponse(tr, "")
+}
+
+func (at *apiTransport) wrapWithDryRunResponse(tr http.RoundTripper, dryRunResponse string) http.RoundTripper {
+	if at.replayer != nil {
+		tr = at.replayer
+	} else {
+		tr = serviceutil.NewRetryTransport(tr)
+		if at.recorder != nil {
+			tr = at.recorder.Transport(tr)
+		}
+	}
+	if at.dryRunWriter != nil {
+		dt := serviceutil.NewDryRunTransport(tr, at.dryRunWriter)
+		dt.Response = dryRunResponse
+		tr = dt
+	}
+	return tr
 }
 
 func insecureSkipVerify() bool {
 	return os.Getenv("REVIEWDOG_INSECURE_SKIP_VERIFY") == "true"
 }
 
-func giteaService(ctx context.Context, opt *option) (gs *giteaservice.PullRequest, isPR bool, err error) {
+func giteaBuildInfoWithClient(ctx context.Context, at *apiTransport) (*cienv.BuildInfo, bool, *gitea.Client, error) {
+	g, isPR, err := cienv.GetBuildInfo()
+	if err != nil {
+		return nil, isPR, nil, err
+	}
+
+	client, err := giteaClientFromEnv(ctx, at)
+	if err != nil {
+		return nil, isPR, nil, err
+	}
+	return g, isPR, client, nil
+}
+
+func giteaClientFromEnv(ctx context.Context, at *apiTransport) (*gitea.Client, error) {
 	token, err := nonEmptyEnv("REVIEWDOG_GITEA_API_TOKEN")
 	if err != nil {
-		return nil, isPR, err
+		return nil, err
 	}
 
 	giteaAddr := os.Getenv("GITEA_ADDRESS")
 	if giteaAddr == "" {
-		return nil, isPR, errors.New("cannot get Gitea host address from environment variable. Set GITEA_ADDRESS ?")
+		giteaAddr = cienv.GiteaActionsServerURL()
 	}
-
-	g, isPR, err := cienv.GetBuildInfo()
-	if err != nil {
-		return nil, isPR, err
+	if giteaAddr == "" {
+		return nil, errors.New("cannot get Gitea host address from environment variable. Set GITEA_ADDRESS ?")
 	}
 
-	client, err := giteaClient(ctx, giteaAddr, token)
+	return giteaClient(ctx, giteaAddr, token, at)
+}
+
+func giteaService(ctx context.Context, opt *option, at *apiTransport) (gs *giteaservice.PullRequest, isPR bool, err error) {
+	g, isPR, client, err := giteaBuildInfoWithClient(ctx, at)
 	if err != nil {
 		return nil, isPR, err
 	}
@@ -572,6 +833,7 @@ func giteaService(ctx context.Context, opt *option) (gs *giteaservice.PullReques
 			gs.SetMaxCommentsPerReview(i)
 		}
 	}
+	gs.SetFailLevel(failLevel(opt))
 	return gs, true, nil
 }
 
@@ -622,11 +884,11 @@ func getGiteaPullRequestIDByBranchOrCommit(client *gitea.Client, info *cienv.Bui
 	return 0, fmt.Errorf("reviewdog: PullRequest not found, query: %s", strings.Join(query, " "))
 }
 
-func giteaClient(ctx context.Context, url, token string) (*gitea.Client, error) {
+func giteaClient(ctx context.Context, url, token string, at *apiTransport) (*gitea.Client, error) {
 	client, err := gitea.NewClient(url,
 		gitea.SetContext(ctx),
 		gitea.SetToken(token),
-		gitea.SetHTTPClient(newHTTPClient()),
+		gitea.SetHTTPClient(at.newHTTPClient()),
 	)
 	if err != nil {
 		return nil, err
@@ -635,8 +897,8 @@ func giteaClient(ctx context.Context, url, token string) (*gitea.Client, error)
 	return client, client.CheckServerVersionConstraint(">=1.17.0")
 }
 
-func githubService(ctx context.Context, opt *option) (gs *githubservice.PullRequest, isPR bool, err error) {
-	g, client, err := githubBuildInfoWithClient(ctx)
+func githubService(ctx context.Context, opt *option, at *apiTransport) (gs *githubservice.PullRequest, isPR bool, err error) {
+	g, client, err := githubBuildInfoWithClient(ctx, at)
 	if err != nil {
 		return nil, false, err
 	}
@@ -661,8 +923,8 @@ func githubService(ctx context.Context, opt *option) (gs *githubservice.PullRequ
 	return gs, true, nil
 }
 
-func githubCheckService(ctx context.Context, opt *option) (reviewdog.CommentService, reviewdog.DiffService, bool, error) {
-	g, client, err := githubBuildInfoWithClient(ctx)
+func githubCheckService(ctx context.Context, opt *option, at *apiTransport) (reviewdog.CommentService, reviewdog.DiffService, bool, error) {
+	g, client, err := githubBuildInfoWithClient(ctx, at)
 	if err != nil {
 		return nil, nil, false, err
 	}
@@ -684,8 +946,44 @@ func githubCheckService(ctx context.Context, opt *option) (reviewdog.CommentServ
 	return cs, ds, g.PullRequest != 0, nil
 }
 
-func githubActionLogService(ctx context.Context, opt *option) (reviewdog.CommentService, reviewdog.DiffService, bool, error) {
-	g, client, err := githubBuildInfoWithClient(ctx)
+func githubStatusService(ctx context.Context, opt *option, at *apiTransport) (reviewdog.CommentService, reviewdog.DiffService, bool, error) {
+	g, client, err := githubBuildInfoWithClient(ctx, at)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var ds reviewdog.DiffService = &reviewdog.EmptyDiff{}
+	if g.PullRequest != 0 {
+		ds = &githubservice.PullRequestDiffService{
+			Cli:              client,
+			Owner:            g.Owner,
+			Repo:             g.Repo,
+			PR:               g.PullRequest,
+			SHA:              g.SHA,
+			FallBackToGitCLI: true,
+		}
+	}
+	cs := githubservice.NewGitHubStatus(client, g.Owner, g.Repo, g.SHA, toolName(opt), statusTargetURL(), failLevel(opt))
+	return cs, ds, g.PullRequest != 0, nil
+}
+
+// statusTargetURL returns the URL linked from commit statuses.
+func statusTargetURL() string {
+	if u := os.Getenv("REVIEWDOG_STATUS_TARGET_URL"); u != "" {
+		return u
+	}
+	// Check Gitea Actions first as it also sets GitHub Actions variables.
+	if u := cienv.GiteaActionsRunURL(); u != "" {
+		return u
+	}
+	if u := cienv.GitHubActionsRunURL(); u != "" {
+		return u
+	}
+	// https://docs.gitlab.com/ee/ci/variables/predefined_variables.html
+	return os.Getenv("CI_JOB_URL")
+}
+
+func githubActionLogService(ctx context.Context, opt *option, at *apiTransport) (reviewdog.CommentService, reviewdog.DiffService, bool, error) {
+	g, client, err := githubBuildInfoWithClient(ctx, at)
 	if err != nil {
 		return nil, nil, false, err
 	}
@@ -704,7 +1002,7 @@ func githubActionLogService(ctx context.Context, opt *option) (reviewdog.Comment
 	return cs, ds, g.PullRequest != 0, nil
 }
 
-func githubBuildInfoWithClient(ctx context.Context) (*cienv.BuildInfo, *github.Client, error) {
+func githubBuildInfoWithClient(ctx context.Context, at *apiTransport) (*cienv.BuildInfo, *github.Client, error) {
 	token, err := nonEmptyEnv("REVIEWDOG_GITHUB_API_TOKEN")
 	if err != nil {
 		return nil, nil, err
@@ -713,7 +1011,7 @@ func githubBuildInfoWithClient(ctx context.Context) (*cienv.BuildInfo, *github.C
 	if err != nil {
 		return nil, nil, err
 	}
-	client, err := githubClient(ctx, token)
+	client, err := githubClient(ctx, token, at)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -758,8 +1056,8 @@ func getPullRequestIDByBranchOrCommit(ctx context.Context, client *github.Client
 	return *pullRequests.Issues[0].Number, nil
 }
 
-func githubClient(ctx context.Context, token string) (*github.Client, error) {
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, newHTTPClient())
+func githubClient(ctx context.Context, token string, at *apiTransport) (*github.Client, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, at.newHTTPClient())
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
@@ -804,7 +1102,7 @@ func githubBaseURL() (*url.URL, error) {
 	return u, nil
 }
 
-func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
+func gitlabBuildWithClient(at *apiTransport) (*cienv.BuildInfo, *gitlab.Client, error) {
 	token, err := nonEmptyEnv("REVIEWDOG_GITLAB_API_TOKEN")
 	if err != nil {
 		return nil, nil, err
@@ -815,7 +1113,7 @@ func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
 		return nil, nil, err
 	}
 
-	client, err := gitlabClient(token)
+	client, err := gitlabClient(token, at)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -833,34 +1131,38 @@ func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
 	return g, client, err
 }
 
-func gerritBuildWithClient() (*cienv.BuildInfo, *gerrit.Client, error) {
+func gerritBuildWithClient(at *apiTransport) (*cienv.BuildInfo, *gerrit.Client, *gerritservice.RESTClient, error) {
 	buildInfo, err := cienv.GetGerritBuildInfo()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	gerritAddr := os.Getenv("GERRIT_ADDRESS")
 	if gerritAddr == "" {
-		return nil, nil, errors.New("cannot get gerrit host address from environment variable. Set GERRIT_ADDRESS ?")
+		return nil, nil, nil, errors.New("cannot get gerrit host address from environment variable. Set GERRIT_ADDRESS ?")
 	}
 
+	var auth gerrit.Auth = gerrit.NoAuth
+	// Gerrit JSON responses begin with an XSSI-defeating prefix, which fake
+	// responses in dry-run mode need as well.
+	httpClient := at.newHTTPClientWithDryRunResponse(gerritservice.DryRunResponse)
+	rest := gerritservice.NewRESTClient(gerritAddr, httpClient)
 	username := os.Getenv("GERRIT_USERNAME")
 	password := os.Getenv("GERRIT_PASSWORD")
 	if username != "" && password != "" {
-		client := gerrit.NewClient(gerritAddr, gerrit.BasicAuth(username, password))
-		return buildInfo, client, nil
-	}
-
-	if useGitCookiePath := os.Getenv("GERRIT_GIT_COOKIE_PATH"); useGitCookiePath != "" {
-		client := gerrit.NewClient(gerritAddr, gerrit.GitCookieFileAuth(useGitCookiePath))
-		return buildInfo, client, nil
+		auth = gerrit.BasicAuth(username, password)
+		rest.SetBasicAuth(username, password)
+	} else if useGitCookiePath := os.Getenv("GERRIT_GIT_COOKIE_PATH"); useGitCookiePath != "" {
+		auth = gerrit.GitCookieFileAuth(useGitCookiePath)
+		rest.SetGitCookieFile(useGitCookiePath)
 	}
 
-	client := gerrit.NewClient(gerritAddr, gerrit.NoAuth)
-	return buildInfo, client, nil
+	client := gerrit.NewClient(gerritAddr, auth)
+	client.HTTPClient = httpClient
+	return buildInfo, client, rest, nil
 }
 
-func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.APIClient, context.Context, error) {
+func bitbucketBuildWithClient(ctx context.Context, at *apiTransport) (*cienv.BuildInfo, bbservice.APIClient, context.Context, error) {
 	build, _, err := cienv.GetBuildInfo()
 	if err != nil {
 		return nil, nil, ctx, err
@@ -877,10 +1179,10 @@ func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.
 		if err != nil {
 			return nil, nil, ctx, fmt.Errorf("failed to build context for Bitbucket API calls: %w", err)
 		}
-		client = bbservice.NewServerAPIClient()
+		client = bbservice.NewServerAPIClient(bbservice.WithTransportWrapper(at.wrap))
 	} else {
 		ctx = bbservice.BuildCloudAPIContext(ctx, bbUser, bbPass, bbAccessToken)
-		client = bbservice.NewCloudAPIClient(cienv.IsInBitbucketPipeline(), cienv.IsInBitbucketPipe())
+		client = bbservice.NewCloudAPIClient(cienv.IsInBitbucketPipeline(), cienv.IsInBitbucketPipe(), bbservice.WithTransportWrapper(at.wrap))
 	}
 
 	return build, client, ctx, nil
@@ -904,12 +1206,17 @@ func fetchMergeRequestIDFromCommit(cli *gitlab.Client, projectID, sha string) (i
 	return 0, nil
 }
 
-func gitlabClient(token string) (*gitlab.Client, error) {
+func gitlabClient(token string, at *apiTransport) (*gitlab.Client, error) {
 	baseURL, err := gitlabBaseURL()
 	if err != nil {
 		return nil, err
 	}
-	client, err := gitlab.NewClient(token, gitlab.WithHTTPClient(newHTTPClient()), gitlab.WithBaseURL(baseURL.String()))
+	client, err := gitlab.NewClient(token,
+		gitlab.WithHTTPClient(at.newHTTPClient()),
+		gitlab.WithBaseURL(baseURL.String()),
+		// Retries are handled by apiTransport.
+		gitlab.WithoutRetries(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1051,6 +1358,13 @@ func localDiffService(opt *option) (reviewdog.DiffService, error) {
 	return diffService(opt.diffCmd, opt.diffStrip)
 }
 
+func commentTemplate(opt *option) (*template.Template, error) {
+	if opt.commentTemplate == "" {
+		return nil, nil
+	}
+	return commentutil.ParseTemplate("comment-template", opt.commentTemplate)
+}
+
 func failLevel(opt *option) reviewdog.FailLevel {
 	if opt.failOnError {
 		slog.Warn("reviewdog: -fail-on-error is deprecated. Use -fail-level=any, or -fail-level=error for github-[pr-]check reporter instead. See also https://github.com/reviewdog/reviewdog/blob/master/CHANGELOG.md")
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -217,3 +219,87 @@ func TestRun_version(t *testing.T) {
 		t.Errorf("version = %v, want %v", got, commands.Version)
 	}
 }
+
+func TestRun_autoRequiresName(t *testing.T) {
+	opt := &option{
+		f:        "auto",
+		reporter: "local",
+	}
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("want error when -f=auto is set without -name")
+	}
+}
+
+func TestRun_httpRecordAndReplayExclusive(t *testing.T) {
+	opt := &option{
+		reporter:   "local",
+		httpRecord: t.TempDir(),
+		httpReplay: t.TempDir(),
+	}
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("want error when both -http-record and -http-replay are set")
+	}
+}
+
+func TestRun_httpRecordAndReplay(t *testing.T) {
+	var reviews int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /changes/changeID/revisions/revisionID/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `)]}'
+{"main.go": {"status": "A"}}`)
+	})
+	mux.HandleFunc("GET /changes/changeID/revisions/revisionID/files/main.go/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `)]}'
+{"content": [{"b": ["package main", "", "func main() {}"]}]}`)
+	})
+	mux.HandleFunc("GET /changes/changeID/{endpoint}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ")]}'\n{}")
+	})
+	mux.HandleFunc("POST /changes/changeID/revisions/revisionID/review", func(w http.ResponseWriter, r *http.Request) {
+		reviews++
+		fmt.Fprint(w, ")]}'\n{}")
+	})
+	ts := httptest.NewServer(mux)
+
+	t.Setenv("GERRIT_CHANGE_ID", "changeID")
+	t.Setenv("GERRIT_REVISION_ID", "revisionID")
+	t.Setenv("GERRIT_BRANCH", "master")
+	t.Setenv("GERRIT_ADDRESS", ts.URL)
+	t.Setenv("GERRIT_API_DIFF", "true")
+
+	dir := t.TempDir()
+	newOpt := func() *option {
+		return &option{
+			efms:     strslice([]string{`%f:%l: %m`}),
+			reporter: "gerrit-change-review",
+		}
+	}
+	const stdin = "main.go:3: message"
+
+	opt := newOpt()
+	opt.httpRecord = dir
+	if err := run(strings.NewReader(stdin), new(bytes.Buffer), opt); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if reviews != 1 {
+		t.Fatalf("record: got %d reviews, want 1", reviews)
+	}
+	fixtures, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("record: no fixtures are saved")
+	}
+
+	// Replay without network access.
+	ts.Close()
+	opt = newOpt()
+	opt.httpReplay = dir
+	if err := run(strings.NewReader(stdin), new(bytes.Buffer), opt); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if reviews != 1 {
+		t.Errorf("replay: got %d reviews, want no new requests to the server", reviews)
+	}
+}
@@ -3,6 +3,7 @@ package reviewdog
 import "context"
 
 var _ BulkCommentService = (*multiCommentService)(nil)
+var _ FinishCommentService = (*multiCommentService)(nil)
 
 type multiCommentService struct {
 	services []CommentService
@@ -37,6 +38,17 @@ func (m *multiCommentService) Flush(ctx context.Context) error {
 	return nil
 }
 
+func (m *multiCommentService) Finish(ctx context.Context) error {
+	for _, cs := range m.services {
+		if fcs, ok := cs.(FinishCommentService); ok {
+			if err := fcs.Finish(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (m *multiCommentService) SetTool(toolName string, level string) {
 	for _, cs := range m.services {
 		if ncs, ok := cs.(NamedCommentService); ok {
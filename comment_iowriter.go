@@ -8,6 +8,7 @@ import (
 
 	"github.com/haya14busa/go-sarif/sarif"
 	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -178,88 +179,173 @@ func (cw *SARIFCommentWriter) Post(_ context.Context, c *Comment) error {
 func (*SARIFCommentWriter) ShouldPrependGitRelDir() bool { return false }
 
 func (cw *SARIFCommentWriter) Flush(_ context.Context) error {
-	run := sarif.Run{
-		Tool: sarif.Tool{
-			Driver: sarif.ToolComponent{
-				Name:  cw.toolName,
-				Rules: make([]sarif.ReportingDescriptor, 0),
-			},
-		},
-	}
-	seenRules := make(map[string]bool)
+	// Write one run per tool, so that consumers like GitHub code scanning can
+	// distinguish results of each tool.
+	var runs []*sarifRunBuilder
+	runByTool := make(map[string]*sarifRunBuilder)
 	for _, c := range cw.comments {
-		result := sarif.Result{
-			Message: sarif.Message{
-				Text: sarif.String(c.Result.Diagnostic.Message),
-			},
+		name, url := cw.tool(c)
+		rb, ok := runByTool[name]
+		if !ok {
+			rb = newSARIFRunBuilder(name, url)
+			runByTool[name] = rb
+			runs = append(runs, rb)
 		}
-		if code := c.Result.Diagnostic.GetCode(); code.GetValue() != "" {
-			result.RuleID = sarif.String(code.GetValue())
-			if seen := seenRules[code.GetValue()]; !seen {
-				seenRules[code.GetValue()] = true
-				rd := sarif.ReportingDescriptor{
-					ID: code.GetValue(),
-				}
-				if code.GetUrl() != "" {
-					rd.HelpURI = sarif.String(code.GetUrl())
-				}
-				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rd)
-			}
+		if err := rb.addResult(c); err != nil {
+			return err
 		}
-		level := severity2level(c.Result.Diagnostic.GetSeverity())
-		if level != sarif.None {
-			result.Level = &level
-		}
-		artifactLoc := sarif.ArtifactLocation{
-			URI: sarif.String(c.Result.Diagnostic.GetLocation().GetPath()),
+	}
+	slf := sarif.NewSarif()
+	slf.Runs = make([]sarif.Run, 0, len(runs))
+	for _, rb := range runs {
+		slf.Runs = append(slf.Runs, rb.run)
+	}
+	if len(slf.Runs) == 0 {
+		slf.Runs = append(slf.Runs, newSARIFRunBuilder(cw.toolName, "").run)
+	}
+	encoder := json.NewEncoder(cw.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(slf)
+}
+
+// tool returns the tool name and its URL of the comment. The diagnostic
+// source takes precedence over the comment tool name and the writer tool name.
+func (cw *SARIFCommentWriter) tool(c *Comment) (name, url string) {
+	if src := c.Result.Diagnostic.GetSource(); src.GetName() != "" {
+		return src.GetName(), src.GetUrl()
+	}
+	if c.ToolName != "" {
+		return c.ToolName, ""
+	}
+	return cw.toolName, ""
+}
+
+// sarifPartialFingerprintKey is a key of SARIF partialFingerprints which
+// holds reviewdog fingerprints of diagnostics.
+const sarifPartialFingerprintKey = "reviewdogFingerprint/v1"
+
+type sarifRunBuilder struct {
+	run       sarif.Run
+	ruleIndex map[string]int64
+}
+
+func newSARIFRunBuilder(name, url string) *sarifRunBuilder {
+	rb := &sarifRunBuilder{
+		run: sarif.Run{
+			Tool: sarif.Tool{
+				Driver: sarif.ToolComponent{
+					Name:  name,
+					Rules: make([]sarif.ReportingDescriptor, 0),
+				},
+			},
+			Results: make([]sarif.Result, 0),
+		},
+		ruleIndex: make(map[string]int64),
+	}
+	if url != "" {
+		rb.run.Tool.Driver.InformationURI = sarif.String(url)
+	}
+	return rb
+}
+
+func (rb *sarifRunBuilder) addResult(c *Comment) error {
+	d := c.Result.Diagnostic
+	result := sarif.Result{
+		Message: sarif.Message{
+			Text: sarif.String(d.Message),
+		},
+	}
+	if code := d.GetCode(); code.GetValue() != "" {
+		result.RuleID = sarif.String(code.GetValue())
+		result.RuleIndex = sarif.Int64(rb.rule(code))
+	}
+	level := severity2level(d.GetSeverity())
+	if level != sarif.None {
+		result.Level = &level
+	}
+	fprint, err := serviceutil.Fingerprint(d)
+	if err != nil {
+		return err
+	}
+	result.PartialFingerprints = map[string]string{sarifPartialFingerprintKey: fprint}
+	artifactLoc := sarif.ArtifactLocation{
+		URI: sarif.String(d.GetLocation().GetPath()),
+	}
+	result.Locations = []sarif.Location{{
+		PhysicalLocation: &sarif.PhysicalLocation{
+			ArtifactLocation: &artifactLoc,
+			Region:           range2region(d.GetLocation().GetRange()),
+		},
+	}}
+	for _, suggestion := range d.GetSuggestions() {
+		if suggestion.GetRange().GetStart().GetLine() == 0 {
+			// A replacement requires the deleted region.
+			continue
 		}
-		result.Locations = []sarif.Location{{
+		result.Fixes = append(result.Fixes, sarif.Fix{
+			ArtifactChanges: []sarif.ArtifactChange{
+				{
+					ArtifactLocation: artifactLoc,
+					Replacements: []sarif.Replacement{{
+						DeletedRegion: *range2region(suggestion.GetRange()),
+						InsertedContent: &sarif.ArtifactContent{
+							Text: sarif.String(suggestion.GetText()),
+						},
+					}},
+				},
+			},
+		})
+	}
+	var lastStep int32
+	for _, relLoc := range d.GetRelatedLocations() {
+		loc := sarif.Location{
 			PhysicalLocation: &sarif.PhysicalLocation{
-				ArtifactLocation: &artifactLoc,
-				Region:           range2region(c.Result.Diagnostic.GetLocation().GetRange()),
+				ArtifactLocation: &sarif.ArtifactLocation{
+					URI: sarif.String(relLoc.GetLocation().GetPath()),
+				},
+				Region: range2region(relLoc.GetLocation().GetRange()),
+			},
+			Message: &sarif.Message{
+				Text: sarif.String(relLoc.Message),
 			},
-		}}
-		if len(c.Result.Diagnostic.GetSuggestions()) > 0 {
-			result.Fixes = make([]sarif.Fix, 0)
-			for _, suggestion := range c.Result.Diagnostic.GetSuggestions() {
-				result.Fixes = append(result.Fixes, sarif.Fix{
-					ArtifactChanges: []sarif.ArtifactChange{
-						{
-							ArtifactLocation: artifactLoc,
-							Replacements: []sarif.Replacement{{
-								DeletedRegion: *range2region(suggestion.GetRange()),
-								InsertedContent: &sarif.ArtifactContent{
-									Text: sarif.String(suggestion.GetText()),
-								},
-							}},
-						},
-					},
-				})
-			}
 		}
-		if len(c.Result.Diagnostic.GetRelatedLocations()) > 0 {
-			result.RelatedLocations = make([]sarif.Location, 0)
-			for _, relLoc := range c.Result.Diagnostic.GetRelatedLocations() {
-				result.RelatedLocations = append(result.RelatedLocations, sarif.Location{
-					PhysicalLocation: &sarif.PhysicalLocation{
-						ArtifactLocation: &sarif.ArtifactLocation{
-							URI: sarif.String(relLoc.GetLocation().GetPath()),
-						},
-						Region: range2region(relLoc.GetLocation().GetRange()),
-					},
-					Message: &sarif.Message{
-						Text: sarif.String(relLoc.Message),
-					},
-				})
-			}
+		if relLoc.GetStep() == 0 {
+			result.RelatedLocations = append(result.RelatedLocations, loc)
+			continue
 		}
-		run.Results = append(run.Results, result)
+		// Steps of a trace are written as a thread flow. A step which
+		// doesn't follow the previous one starts a new code flow.
+		n := len(result.CodeFlows)
+		if n == 0 || relLoc.GetStep() <= lastStep {
+			result.CodeFlows = append(result.CodeFlows, sarif.CodeFlow{
+				ThreadFlows: []sarif.ThreadFlow{{}},
+			})
+			n++
+		}
+		threadFlow := &result.CodeFlows[n-1].ThreadFlows[0]
+		threadFlow.Locations = append(threadFlow.Locations, sarif.ThreadFlowLocation{Location: &loc})
+		lastStep = relLoc.GetStep()
 	}
-	slf := sarif.NewSarif()
-	slf.Runs = []sarif.Run{run}
-	encoder := json.NewEncoder(cw.w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(slf)
+	rb.run.Results = append(rb.run.Results, result)
+	return nil
+}
+
+// rule returns the index of the rule in the driver rules, adding the rule
+// if it's not added yet.
+func (rb *sarifRunBuilder) rule(code *rdf.Code) int64 {
+	if i, ok := rb.ruleIndex[code.GetValue()]; ok {
+		return i
+	}
+	rd := sarif.ReportingDescriptor{
+		ID: code.GetValue(),
+	}
+	if code.GetUrl() != "" {
+		rd.HelpURI = sarif.String(code.GetUrl())
+	}
+	i := int64(len(rb.run.Tool.Driver.Rules))
+	rb.run.Tool.Driver.Rules = append(rb.run.Tool.Driver.Rules, rd)
+	rb.ruleIndex[code.GetValue()] = i
+	return i
 }
 
 func range2region(rng *rdf.Range) *sarif.Region {
@@ -7,7 +7,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/parser"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
@@ -300,6 +304,20 @@ func TestSARIFCommentWriter_Post(t *testing.T) {
 						Name: "tool name in Diagnostic",
 						Url:  "tool url",
 					},
+					Code: &rdf.Code{
+						Value: "rule-id",
+						Url:   "https://example.com/rule-id",
+					},
+					Severity: rdf.Severity_WARNING,
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{
+								Start: &rdf.Position{Line: 1, Column: 14},
+								End:   &rdf.Position{Line: 1, Column: 16},
+							},
+							Text: "fixed",
+						},
+					},
 				},
 			},
 		},
@@ -333,8 +351,20 @@ func TestSARIFCommentWriter_Post(t *testing.T) {
           ],
           "message": {
             "text": "message"
+          },
+          "partialFingerprints": {
+            "reviewdogFingerprint/v1": "31e29add5b21cb10"
           }
-        },
+        }
+      ],
+      "tool": {
+        "driver": {
+          "name": "tool name"
+        }
+      }
+    },
+    {
+      "results": [
         {
           "locations": [
             {
@@ -350,9 +380,46 @@ func TestSARIFCommentWriter_Post(t *testing.T) {
           ],
           "message": {
             "text": "message"
+          },
+          "partialFingerprints": {
+            "reviewdogFingerprint/v1": "2f9c9c6e617169e6"
           }
-        },
+        }
+      ],
+      "tool": {
+        "driver": {
+          "name": "tool name [constructor]"
+        }
+      }
+    },
+    {
+      "results": [
         {
+          "fixes": [
+            {
+              "artifactChanges": [
+                {
+                  "artifactLocation": {
+                    "uri": "/path/to/file"
+                  },
+                  "replacements": [
+                    {
+                      "deletedRegion": {
+                        "endColumn": 16,
+                        "endLine": 1,
+                        "startColumn": 14,
+                        "startLine": 1
+                      },
+                      "insertedContent": {
+                        "text": "fixed"
+                      }
+                    }
+                  ]
+                }
+              ]
+            }
+          ],
+          "level": "warning",
           "locations": [
             {
               "physicalLocation": {
@@ -367,12 +434,24 @@ func TestSARIFCommentWriter_Post(t *testing.T) {
           ],
           "message": {
             "text": "message"
-          }
+          },
+          "partialFingerprints": {
+            "reviewdogFingerprint/v1": "45db71dc4e3ceeb"
+          },
+          "ruleId": "rule-id",
+          "ruleIndex": 0
         }
       ],
       "tool": {
         "driver": {
-          "name": "tool name [constructor]"
+          "informationUri": "tool url",
+          "name": "tool name in Diagnostic",
+          "rules": [
+            {
+              "helpUri": "https://example.com/rule-id",
+              "id": "rule-id"
+            }
+          ]
         }
       }
     }
@@ -384,3 +463,47 @@ func TestSARIFCommentWriter_Post(t *testing.T) {
 		t.Errorf("got\n%v\nwant:\n%v", got, want)
 	}
 }
+
+func TestSARIFCommentWriter_codeFlows(t *testing.T) {
+	loc := func(line int32) *rdf.Location {
+		return &rdf.Location{Path: "file.go", Range: &rdf.Range{Start: &rdf.Position{Line: line}}}
+	}
+	related := []*rdf.RelatedLocation{
+		{Location: loc(1), Message: "related"},
+		{Location: loc(2), Message: "source", Step: 1},
+		{Location: loc(3), Message: "propagation", Step: 2},
+		{Location: loc(4), Message: "sink", Step: 3},
+		{Location: loc(5), Message: "another source", Step: 1},
+		{Location: loc(6), Message: "another sink", Step: 2},
+	}
+	buf := new(bytes.Buffer)
+	cw := NewSARIFCommentWriter(buf, "tool")
+	if err := cw.Post(context.Background(), &Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location:         loc(4),
+				Message:          "tainted",
+				RelatedLocations: related,
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), `"threadFlows"`); got != 2 {
+		t.Errorf("got %d thread flows, want 2:\n%s", got, buf.String())
+	}
+
+	ds, err := parser.NewSarifParser().Parse(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	if diff := cmp.Diff(related, ds[0].GetRelatedLocations(), protocmp.Transform()); diff != "" {
+		t.Errorf("related locations are not round-tripped (-want +got):\n%s", diff)
+	}
+}
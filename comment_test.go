@@ -56,3 +56,25 @@ func TestMultiCommentService_Flush(t *testing.T) {
 		t.Error("MultiCommentService_Flush should run Flush() for every services")
 	}
 }
+
+type fakeFinishCommentService struct {
+	FinishCommentService
+	calledFinish bool
+}
+
+func (f *fakeFinishCommentService) Finish(_ context.Context) error {
+	f.calledFinish = true
+	return nil
+}
+
+func TestMultiCommentService_Finish(t *testing.T) {
+	f1 := &fakeFinishCommentService{}
+	f2 := &fakeBulkCommentService{}
+	w := MultiCommentService(f1, f2)
+	if err := w.(FinishCommentService).Finish(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !f1.calledFinish {
+		t.Error("MultiCommentService_Finish should run Finish() for every services which support it")
+	}
+}
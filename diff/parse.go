@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"strconv"
 	"strings"
 )
@@ -42,21 +43,36 @@ func ParseMultiFile(r io.Reader) ([]*FileDiff, error) {
 	return (&multiFileParser{r: bufio.NewReader(r)}).Parse()
 }
 
+// ParseMultiFileSeq parses a multi-file unified diff and yields each file diff
+// as soon as it's parsed. Like ParseMultiFile, it stops at the first invalid
+// file diff.
+func ParseMultiFileSeq(r io.Reader) iter.Seq[*FileDiff] {
+	return (&multiFileParser{r: bufio.NewReader(r)}).All
+}
+
 type multiFileParser struct {
 	r *bufio.Reader
 }
 
 func (p *multiFileParser) Parse() ([]*FileDiff, error) {
 	var fds []*FileDiff
+	for fd := range p.All {
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
+
+func (p *multiFileParser) All(yield func(*FileDiff) bool) {
 	fp := &fileParser{r: p.r}
 	for {
 		fd, err := fp.Parse()
 		if err != nil || fd == nil {
-			break
+			return
+		}
+		if !yield(fd) {
+			return
 		}
-		fds = append(fds, fd)
 	}
-	return fds, nil
 }
 
 // ParseFile parses a file unified diff.
@@ -30,6 +30,9 @@ type FilteredDiagnostic struct {
 
 	OldPath string
 	OldLine int
+	// Old line of the end line if it's a multiline result. Zero if it's a
+	// single line result or the end line is an added line.
+	OldEndLine int
 }
 
 // FilterCheck filters check results by diff. It doesn't drop check which
@@ -41,49 +44,59 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 	checks := make([]*FilteredDiagnostic, 0, len(results))
 	df := NewDiffFilter(diff, strip, cwd, mode)
 	for _, result := range results {
-		check := &FilteredDiagnostic{Diagnostic: result, SourceLines: make(map[int]string)}
-		loc := result.GetLocation()
-		startLine := int(loc.GetRange().GetStart().GetLine())
-		endLine := int(loc.GetRange().GetEnd().GetLine())
-		if endLine == 0 {
-			endLine = startLine
+		checks = append(checks, df.FilterDiagnostic(result))
+	}
+	return checks
+}
+
+// FilterDiagnostic filters a check result by diff like FilterCheck. It's
+// useful to filter results one by one as they are parsed.
+//
+// The path in the result should be normalized before calling this function.
+func (df *DiffFilter) FilterDiagnostic(result *rdf.Diagnostic) *FilteredDiagnostic {
+	check := &FilteredDiagnostic{Diagnostic: result, SourceLines: make(map[int]string)}
+	loc := result.GetLocation()
+	startLine := int(loc.GetRange().GetStart().GetLine())
+	endLine := int(loc.GetRange().GetEnd().GetLine())
+	if endLine == 0 {
+		endLine = startLine
+	}
+	check.InDiffContext = true
+	for l := startLine; l <= endLine; l++ {
+		shouldReport, difffile, diffline := df.ShouldReport(loc.GetPath(), l)
+		check.ShouldReport = check.ShouldReport || shouldReport
+		// all lines must be in diff.
+		check.InDiffContext = check.InDiffContext && diffline != nil
+		if diffline != nil {
+			check.SourceLines[l] = diffline.Content
 		}
-		check.InDiffContext = true
-		for l := startLine; l <= endLine; l++ {
-			shouldReport, difffile, diffline := df.ShouldReport(loc.GetPath(), l)
-			check.ShouldReport = check.ShouldReport || shouldReport
-			// all lines must be in diff.
-			check.InDiffContext = check.InDiffContext && diffline != nil
-			if diffline != nil {
-				check.SourceLines[l] = diffline.Content
+		if difffile != nil {
+			check.InDiffFile = true
+			if l == startLine {
+				check.OldPath, check.OldLine = getOldPosition(difffile, df.strip, loc.GetPath(), l)
 			}
-			if difffile != nil {
-				check.InDiffFile = true
-				if l == startLine {
-					// TODO(haya14busa): Support endline as well especially for GitLab.
-					check.OldPath, check.OldLine = getOldPosition(difffile, strip, loc.GetPath(), l)
-				}
+			if l == endLine && endLine != startLine {
+				_, check.OldEndLine = getOldPosition(difffile, df.strip, loc.GetPath(), l)
 			}
 		}
-		// Add source lines for suggestions.
-		for i, s := range result.GetSuggestions() {
-			inDiffContext := true
-			start := int(s.GetRange().GetStart().GetLine())
-			end := int(s.GetRange().GetEnd().GetLine())
-			for l := start; l <= end; l++ {
-				if diffline := df.DiffLine(loc.GetPath(), l); diffline != nil {
-					check.SourceLines[l] = diffline.Content
-				} else {
-					inDiffContext = false
-				}
-			}
-			if i == 0 {
-				check.FirstSuggestionInDiffContext = inDiffContext
+	}
+	// Add source lines for suggestions.
+	for i, s := range result.GetSuggestions() {
+		inDiffContext := true
+		start := int(s.GetRange().GetStart().GetLine())
+		end := int(s.GetRange().GetEnd().GetLine())
+		for l := start; l <= end; l++ {
+			if diffline := df.DiffLine(loc.GetPath(), l); diffline != nil {
+				check.SourceLines[l] = diffline.Content
+			} else {
+				inDiffContext = false
 			}
 		}
-		checks = append(checks, check)
+		if i == 0 {
+			check.FirstSuggestionInDiffContext = inDiffContext
+		}
 	}
-	return checks
+	return check
 }
 
 func getOldPosition(filediff *diff.FileDiff, strip int, newPath string, newLine int) (oldPath string, oldLine int) {
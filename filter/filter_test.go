@@ -577,6 +577,28 @@ func findFileDiff(filediffs []*diff.FileDiff, path string, strip int) *diff.File
 	return nil
 }
 
+func TestFilterCheck_oldEndLine(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{
+			Location: &rdf.Location{
+				Path: "sample.new.txt",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 1},
+					End:   &rdf.Position{Line: 4},
+				},
+			},
+		},
+	}
+	filediffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
+	got := FilterCheck(results, filediffs, 0, "", ModeDiffContext)
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	if got[0].OldLine != 1 || got[0].OldEndLine != 3 {
+		t.Errorf("got (OldLine, OldEndLine) = (%d, %d), want (1, 3)", got[0].OldLine, got[0].OldEndLine)
+	}
+}
+
 func TestGetOldPosition(t *testing.T) {
 	const strip = 0
 	filediffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
@@ -0,0 +1,333 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/reviewdog/errorformat/fmts"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ StreamParser = &AutoParser{}
+
+// sniffSize is the size of the input prefix to detect the format from.
+const sniffSize = 64 << 10
+
+// AutoParser is parser which detects the input format and parses the input
+// with the parser of the detected format.
+//
+// It detects the format from the first 64KB of the input only, and streams
+// the input through the parser of the detected format.
+type AutoParser struct {
+	diffStrip int
+}
+
+// NewAutoParser returns a new AutoParser. diffStrip is used if the input is
+// detected as diff.
+func NewAutoParser(diffStrip int) *AutoParser {
+	return &AutoParser{diffStrip: diffStrip}
+}
+
+func (p *AutoParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	return collect(p.ParseStream(r))
+}
+
+// ParseStream detects the input format and yields diagnostics parsed by the
+// parser of the detected format. Empty (or whitespace only) input yields no
+// diagnostics.
+func (p *AutoParser) ParseStream(r io.Reader) iter.Seq2[*rdf.Diagnostic, error] {
+	return func(yield func(*rdf.Diagnostic, error) bool) {
+		prefix, err := io.ReadAll(io.LimitReader(r, sniffSize))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if len(prefix) < sniffSize && len(bytes.TrimSpace(prefix)) == 0 {
+			return
+		}
+		name, err := DetectFormat(prefix)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		slog.Info("reviewdog: detected input format", "format", name)
+		parser, err := New(&Option{FormatName: name, DiffStrip: p.diffStrip})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for d, err := range Stream(parser, io.MultiReader(bytes.NewReader(prefix), r)) {
+			if !yield(d, err) {
+				return
+			}
+		}
+	}
+}
+
+// DetectFormat detects the format of the input and returns its format name.
+// The input may be a prefix of the whole input, in which case a JSON value
+// truncated at the end is detected by its beginning.
+//
+// Structured formats (checkstyle, SARIF, rdjson, rdjsonl, diff and JSON
+// formats of tools) are detected by their structure. Otherwise, the input is
+// parsed with all pre-defined errorformats and the format with the most
+// diagnostics is returned. As many errorformats match generic
+// "file:line:col: message" lines, ties are broken by the number of extracted
+// columns, severities and codes, and then by the format name order.
+func DetectFormat(input []byte) (string, error) {
+	if name := detectStructuredFormat(input); name != "" {
+		return name, nil
+	}
+	efms := fmts.DefinedFmts()
+	best, bestCount, bestDetail := "", 0, 0
+	for _, name := range slices.Sorted(maps.Keys(efms)) {
+		p, err := NewErrorformatParserString(efms[name].Errorformat)
+		if err != nil {
+			continue
+		}
+		ds, err := p.Parse(bytes.NewReader(input))
+		if err != nil {
+			continue
+		}
+		detail := diagnosticDetail(ds)
+		if len(ds) > bestCount || (len(ds) == bestCount && detail > bestDetail) {
+			best, bestCount, bestDetail = name, len(ds), detail
+		}
+	}
+	if best == "" {
+		return "", errors.New("failed to detect input format. Specify the format with -f or -efm")
+	}
+	return best, nil
+}
+
+// diagnosticDetail returns the number of optional fields extracted by an
+// errorformat.
+func diagnosticDetail(ds []*rdf.Diagnostic) int {
+	n := 0
+	for _, d := range ds {
+		if d.GetLocation().GetRange().GetStart().GetColumn() > 0 {
+			n++
+		}
+		if d.GetSeverity() != rdf.Severity_UNKNOWN_SEVERITY {
+			n++
+		}
+		if d.GetCode().GetValue() != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func detectStructuredFormat(input []byte) string {
+	text := bytes.TrimSpace(input)
+	if len(text) == 0 {
+		return ""
+	}
+	switch text[0] {
+	case '<':
+		if bytes.Contains(text, []byte("<checkstyle")) {
+			return "checkstyle"
+		}
+		return ""
+	case '{', '[':
+		return detectJSONFormat(text)
+	case '#':
+		// go vet -json outputs "# <package>" lines followed by JSON objects.
+		if goVetJSON(text) {
+			return "govet-json"
+		}
+		return ""
+	}
+	if isDiff(text) {
+		return "diff"
+	}
+	return ""
+}
+
+func detectJSONFormat(text []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	first, err := decodePartialJSON(dec)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return ""
+	}
+	// Inputs with multiple JSON values are JSON lines or outputs of multiple
+	// invocations.
+	multiple := dec.More()
+	switch v := first.(type) {
+	case []any:
+		if len(v) == 0 {
+			return ""
+		}
+		elem, _ := v[0].(map[string]any)
+		switch {
+		case hasKeys(elem, "filePath", "messages"):
+			return "eslint-json"
+		case hasKeys(elem, "kind", "locations"):
+			return "gcc-json"
+		}
+	case map[string]any:
+		switch {
+		case hasKeys(v, "runs"), hasKeys(v, "$schema", "version"):
+			if isClangSarif(v) {
+				return "clang-sarif"
+			}
+			return "sarif"
+		case hasKeys(v, "diagnostics") && !multiple:
+			return "rdjson"
+		case hasKeys(v, "results", "metadata"):
+			return "eslint-json"
+		case hasKeys(v, "reason"), hasKeys(v, "$message_type"):
+			return "cargo-json"
+		case hasKeys(v, "message", "location"):
+			return "rdjsonl"
+		case isGoVetJSONTree(v):
+			return "govet-json"
+		}
+	}
+	return ""
+}
+
+// decodePartialJSON decodes the next JSON value from dec. If the input ends in
+// the middle of the value, it returns the value decoded so far with
+// io.ErrUnexpectedEOF.
+func decodePartialJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := make(map[string]any)
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return m, unexpectedEOF(err)
+			}
+			k, _ := key.(string)
+			v, err := decodePartialJSON(dec)
+			m[k] = v
+			if err != nil {
+				return m, unexpectedEOF(err)
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return m, unexpectedEOF(err)
+		}
+		return m, nil
+	case json.Delim('['):
+		a := []any{}
+		for dec.More() {
+			v, err := decodePartialJSON(dec)
+			a = append(a, v)
+			if err != nil {
+				return a, unexpectedEOF(err)
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return a, unexpectedEOF(err)
+		}
+		return a, nil
+	}
+	return tok, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func hasKeys(m map[string]any, keys ...string) bool {
+	if m == nil {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isClangSarif(v map[string]any) bool {
+	runs, _ := v["runs"].([]any)
+	for _, run := range runs {
+		run, _ := run.(map[string]any)
+		tool, _ := run["tool"].(map[string]any)
+		driver, _ := tool["driver"].(map[string]any)
+		if name, _ := driver["name"].(string); strings.EqualFold(name, "clang") {
+			return true
+		}
+	}
+	return false
+}
+
+// isGoVetJSONTree reports whether v is package ID -> analyzer name ->
+// diagnostics (or error) tree of go vet -json output.
+func isGoVetJSONTree(v map[string]any) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for _, analyzers := range v {
+		analyzers, ok := analyzers.(map[string]any)
+		if !ok {
+			return false
+		}
+		for _, diags := range analyzers {
+			switch diags := diags.(type) {
+			case []any:
+				for _, d := range diags {
+					if d, _ := d.(map[string]any); !hasKeys(d, "posn", "message") {
+						return false
+					}
+				}
+			case map[string]any:
+				if !hasKeys(diags, "error") {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func goVetJSON(text []byte) bool {
+	s := bufio.NewScanner(bytes.NewReader(text))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.HasPrefix(line, "{")
+	}
+	return false
+}
+
+func isDiff(text []byte) bool {
+	if bytes.HasPrefix(text, []byte("diff --git ")) || bytes.Contains(text, []byte("\ndiff --git ")) {
+		return true
+	}
+	// Unified diff without git header.
+	s := bufio.NewScanner(bytes.NewReader(text))
+	prevOld := false
+	for s.Scan() {
+		line := s.Text()
+		if prevOld && strings.HasPrefix(line, "+++ ") {
+			return true
+		}
+		prevOld = strings.HasPrefix(line, "--- ")
+	}
+	return false
+}
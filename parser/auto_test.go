@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "checkstyle",
+			in: `<?xml version="1.0" encoding="utf-8"?>
+<checkstyle version="4.3"><file name="a.js"><error line="1" column="2" severity="error" message="msg" source="rule" /></file></checkstyle>`,
+			want: "checkstyle",
+		},
+		{
+			name: "sarif",
+			in:   `{"$schema": "https://json.schemastore.org/sarif-2.1.0.json", "version": "2.1.0", "runs": [{"tool": {"driver": {"name": "CodeQL"}}, "results": []}]}`,
+			want: "sarif",
+		},
+		{
+			name: "clang-sarif",
+			in:   `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "clang"}}, "results": []}]}`,
+			want: "clang-sarif",
+		},
+		{
+			name: "rdjson",
+			in:   `{"source": {"name": "linter"}, "diagnostics": [{"message": "msg", "location": {"path": "a.go"}}]}`,
+			want: "rdjson",
+		},
+		{
+			name: "rdjsonl",
+			in: `{"message": "msg1", "location": {"path": "a.go", "range": {"start": {"line": 1}}}}
+{"message": "msg2", "location": {"path": "b.go", "range": {"start": {"line": 2}}}}`,
+			want: "rdjsonl",
+		},
+		{
+			name: "diff",
+			in: `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1 +1 @@
+-a
++b
+`,
+			want: "diff",
+		},
+		{
+			name: "unified diff",
+			in: `--- a.go.orig
++++ a.go
+@@ -1 +1 @@
+-a
++b
+`,
+			want: "diff",
+		},
+		{
+			name: "gcc-json",
+			in:   `[{"kind": "warning", "message": "unused variable", "locations": [{"caret": {"file": "a.c", "line": 1, "column": 1}}]}]`,
+			want: "gcc-json",
+		},
+		{
+			name: "cargo-json",
+			in: `{"reason": "compiler-artifact", "package_id": "app 0.1.0"}
+{"reason": "build-finished", "success": true}`,
+			want: "cargo-json",
+		},
+		{
+			name: "govet-json",
+			in: `# example.com/app
+{"example.com/app": {"printf": [{"posn": "a.go:1:2", "message": "msg"}]}}`,
+			want: "govet-json",
+		},
+		{
+			name: "govet-json without comments",
+			in:   `{"example.com/app": {"printf": [{"posn": "a.go:1:2", "message": "msg"}], "broken": {"error": "failed"}}}`,
+			want: "govet-json",
+		},
+		{
+			name: "eslint-json",
+			in:   `[{"filePath": "/src/a.js", "messages": []}]`,
+			want: "eslint-json",
+		},
+		{
+			name: "eslint-json with metadata",
+			in:   `{"results": [{"filePath": "/src/a.js", "messages": []}], "metadata": {}}`,
+			want: "eslint-json",
+		},
+		{
+			name: "errorformat",
+			in: `src/a.ts(3,5): error TS2322: Type 'string' is not assignable to type 'number'.
+src/b.ts(10,1): error TS1005: ';' expected.`,
+			want: "tsc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFormat_unknown(t *testing.T) {
+	if got, err := DetectFormat([]byte("hello\nworld\n")); err == nil {
+		t.Errorf("DetectFormat() = %q, want error", got)
+	}
+}
+
+func TestAutoParser(t *testing.T) {
+	const sample = `{"message": "msg", "location": {"path": "a.go", "range": {"start": {"line": 14}}}}`
+	ds, err := NewAutoParser(1).Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 || ds[0].GetMessage() != "msg" || ds[0].GetLocation().GetPath() != "a.go" {
+		t.Errorf("unexpected diagnostics: %v", ds)
+	}
+}
+
+func TestAutoParser_empty(t *testing.T) {
+	for _, in := range []string{"", " \n\t\n"} {
+		ds, err := NewAutoParser(1).Parse(strings.NewReader(in))
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", in, err)
+		}
+		if len(ds) != 0 {
+			t.Errorf("Parse(%q) = %v, want no diagnostics", in, ds)
+		}
+	}
+}
+
+func TestAutoParser_largeInput(t *testing.T) {
+	// The format is detected from the beginning of the SARIF JSON, which is
+	// truncated at the sniff size.
+	const n = 2000
+	results := make([]string, n)
+	for i := range results {
+		results[i] = fmt.Sprintf(`{"message": {"text": "msg%d"}, "locations": [{"physicalLocation": {"artifactLocation": {"uri": "a.go"}, "region": {"startLine": %d}}}]}`, i, i+1)
+	}
+	in := `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "linter"}}, "results": [` + strings.Join(results, ",") + `]}]}`
+	if len(in) <= sniffSize {
+		t.Fatalf("input is too small: %d bytes", len(in))
+	}
+	ds, err := NewAutoParser(1).Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != n {
+		t.Errorf("got %d diagnostics, want %d", len(ds), n)
+	}
+}
+
+func TestAutoParser_ParseStream(t *testing.T) {
+	const line = `{"message": "msg", "location": {"path": "a.go", "range": {"start": {"line": 14}}}}` + "\n"
+	n := sniffSize/len(line) + 10
+	pr, pw := io.Pipe()
+	firstYielded := make(chan struct{})
+	go func() {
+		for range n {
+			io.WriteString(pw, line)
+		}
+		// Diagnostics are yielded before the input ends.
+		<-firstYielded
+		pw.Close()
+	}()
+	count := 0
+	for d, err := range NewAutoParser(1).ParseStream(pr) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.GetLocation().GetPath() != "a.go" {
+			t.Errorf("unexpected diagnostic: %v", d)
+		}
+		if count == 0 {
+			close(firstYielded)
+		}
+		count++
+	}
+	if count != n {
+		t.Errorf("got %d diagnostics, want %d", count, n)
+	}
+}
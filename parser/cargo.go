@@ -0,0 +1,285 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &CargoJSONParser{}
+
+// CargoJSONParser is parser for JSON messages of cargo
+// (cargo check/clippy --message-format=json) and rustc
+// (rustc --error-format=json).
+//
+// The primary span is converted to the location and other spans are
+// converted to related locations. Machine applicable suggestions of children
+// are converted to suggestions, one per child.
+type CargoJSONParser struct{}
+
+// NewCargoJSONParser returns a new CargoJSONParser.
+func NewCargoJSONParser() *CargoJSONParser {
+	return &CargoJSONParser{}
+}
+
+// Parse parses JSON lines of cargo messages. Messages other than compiler
+// messages (e.g. compiler-artifact) and non JSON lines are ignored.
+func (p *CargoJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 && line[0] == '{' {
+			d, perr := parseCargoMessage(line)
+			if perr != nil {
+				return nil, perr
+			}
+			if d != nil {
+				ds = append(ds, d)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return ds, nil
+}
+
+func parseCargoMessage(line []byte) (*rdf.Diagnostic, error) {
+	var msg struct {
+		Reason  string           `json:"reason"`
+		Message *RustcDiagnostic `json:"message"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil {
+		// rustc --error-format=json outputs diagnostics as they are, where
+		// "message" is a string.
+		rd := new(RustcDiagnostic)
+		if err := json.Unmarshal(line, rd); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cargo JSON message: %w", err)
+		}
+		return rd.toRDF(string(line)), nil
+	}
+	if msg.Reason != "compiler-message" || msg.Message == nil {
+		return nil, nil
+	}
+	return msg.Message.toRDF(string(line)), nil
+}
+
+func (rd *RustcDiagnostic) toRDF(line string) *rdf.Diagnostic {
+	var primary *RustcSpan
+	for _, span := range rd.Spans {
+		if span.IsPrimary {
+			primary = span
+			break
+		}
+	}
+	if primary == nil {
+		// e.g. "aborting due to 2 previous errors".
+		return nil
+	}
+	d := &rdf.Diagnostic{
+		Message:        rd.Message,
+		Location:       primary.location(),
+		Severity:       rustcSeverity(rd.Level),
+		Code:           rd.Code.toRDF(),
+		OriginalOutput: rd.Rendered,
+	}
+	if d.OriginalOutput == "" {
+		d.OriginalOutput = line
+	}
+	for _, span := range rd.Spans {
+		if span == primary {
+			continue
+		}
+		d.RelatedLocations = append(d.RelatedLocations, &rdf.RelatedLocation{
+			Message:  span.Label,
+			Location: span.location(),
+		})
+	}
+	for _, child := range rd.Children {
+		var related, replacements []*RustcSpan
+		for _, span := range child.Spans {
+			if span.SuggestedReplacement == nil {
+				related = append(related, span)
+				continue
+			}
+			replacements = append(replacements, span)
+		}
+		if s := rustcSuggestion(primary.FileName, replacements); s != nil {
+			d.Suggestions = append(d.Suggestions, s)
+		}
+		for _, span := range related {
+			msg := child.Message
+			if span.Label != "" {
+				msg += ": " + span.Label
+			}
+			d.RelatedLocations = append(d.RelatedLocations, &rdf.RelatedLocation{
+				Message:  msg,
+				Location: span.location(),
+			})
+		}
+		if len(child.Spans) == 0 && child.Message != "" {
+			// Notes and helps without spans are shown in the message.
+			d.Message += fmt.Sprintf("\n%s: %s", child.Level, child.Message)
+		}
+	}
+	return d
+}
+
+// rustcSuggestion merges suggested replacements of spans of a child into a
+// suggestion. It returns nil if the replacements can't be applied as a whole:
+// a replacement isn't machine applicable or is in another file, or multiple
+// replacements aren't on the same line (the source text between them is
+// unknown) or overlap.
+func rustcSuggestion(filename string, spans []*RustcSpan) *rdf.Suggestion {
+	if len(spans) == 0 {
+		return nil
+	}
+	for _, span := range spans {
+		if span.SuggestionApplicability != "MachineApplicable" || span.FileName != filename {
+			return nil
+		}
+	}
+	if len(spans) == 1 {
+		return &rdf.Suggestion{
+			Range: spans[0].location().GetRange(),
+			Text:  *spans[0].SuggestedReplacement,
+		}
+	}
+	spans = slices.Clone(spans)
+	slices.SortStableFunc(spans, func(a, b *RustcSpan) int { return cmp.Compare(a.ColumnStart, b.ColumnStart) })
+	line := spans[0].LineStart
+	var rng *rdf.Range
+	var text strings.Builder
+	for _, span := range spans {
+		if span.LineStart != line || span.LineEnd != line || len(span.Text) == 0 {
+			return nil
+		}
+		r := span.location().GetRange()
+		if rng == nil {
+			rng = r
+		} else {
+			src := span.Text[0].Text
+			from, to := int(rng.GetEnd().GetColumn())-1, int(r.GetStart().GetColumn())-1
+			if from > to || to > len(src) {
+				return nil
+			}
+			text.WriteString(src[from:to])
+			rng.End = r.GetEnd()
+		}
+		text.WriteString(*span.SuggestedReplacement)
+	}
+	return &rdf.Suggestion{Range: rng, Text: text.String()}
+}
+
+func (s *RustcSpan) location() *rdf.Location {
+	start := &rdf.Position{Line: int32(s.LineStart), Column: int32(s.ColumnStart)}
+	end := &rdf.Position{Line: int32(s.LineEnd), Column: int32(s.ColumnEnd)}
+	// Columns of rustc are 1-origin character (Unicode scalar value) offsets,
+	// while rdf columns are byte offsets. Convert them with the source text of
+	// the span if available.
+	if len(s.Text) > 0 {
+		start.Column = byteColumn(s.Text[0].Text, s.ColumnStart)
+		end.Column = byteColumn(s.Text[len(s.Text)-1].Text, s.ColumnEnd)
+	}
+	return &rdf.Location{
+		Path:  s.FileName,
+		Range: &rdf.Range{Start: start, End: end},
+	}
+}
+
+// byteColumn converts 1-origin character column in the line to 1-origin byte
+// column.
+func byteColumn(line string, charColumn int) int32 {
+	if charColumn <= 0 {
+		return 0
+	}
+	n := 0
+	for i := range line {
+		if n == charColumn-1 {
+			return int32(i + 1)
+		}
+		n++
+	}
+	// At or beyond the end of the line.
+	return int32(len(line) + charColumn - n)
+}
+
+func (c *RustcCode) toRDF() *rdf.Code {
+	if c == nil || c.Code == "" {
+		return nil
+	}
+	code := &rdf.Code{Value: c.Code}
+	switch {
+	case strings.HasPrefix(c.Code, "clippy::"):
+		code.Url = "https://rust-lang.github.io/rust-clippy/master/index.html#" + strings.TrimPrefix(c.Code, "clippy::")
+	case c.Explanation != nil:
+		code.Url = "https://doc.rust-lang.org/error_codes/" + c.Code + ".html"
+	}
+	return code
+}
+
+func rustcSeverity(level string) rdf.Severity {
+	switch {
+	case strings.HasPrefix(level, "error"):
+		// "error" or "error: internal compiler error".
+		return rdf.Severity_ERROR
+	case level == "warning":
+		return rdf.Severity_WARNING
+	case level == "note", level == "help", level == "failure-note":
+		return rdf.Severity_INFO
+	}
+	return rdf.Severity_UNKNOWN_SEVERITY
+}
+
+// RustcDiagnostic represents a rustc JSON diagnostic.
+//
+// References:
+//   - https://doc.rust-lang.org/rustc/json.html
+//   - https://doc.rust-lang.org/cargo/reference/external-tools.html#json-messages
+type RustcDiagnostic struct {
+	Message  string             `json:"message"`
+	Code     *RustcCode         `json:"code"`
+	Level    string             `json:"level"`
+	Spans    []*RustcSpan       `json:"spans"`
+	Children []*RustcDiagnostic `json:"children"`
+	Rendered string             `json:"rendered"`
+}
+
+// RustcCode represents a diagnostic code. Explanation is non-nil if the code
+// has an explanation (i.e. rustc --explain).
+type RustcCode struct {
+	Code        string  `json:"code"`
+	Explanation *string `json:"explanation"`
+}
+
+// RustcSpan represents a span in a file. Lines and columns are 1-origin and
+// the end column is exclusive.
+type RustcSpan struct {
+	FileName                string           `json:"file_name"`
+	LineStart               int              `json:"line_start"`
+	LineEnd                 int              `json:"line_end"`
+	ColumnStart             int              `json:"column_start"`
+	ColumnEnd               int              `json:"column_end"`
+	IsPrimary               bool             `json:"is_primary"`
+	Text                    []*RustcSpanLine `json:"text"`
+	Label                   string           `json:"label"`
+	SuggestedReplacement    *string          `json:"suggested_replacement"`
+	SuggestionApplicability string           `json:"suggestion_applicability"`
+}
+
+// RustcSpanLine represents a source line of a span.
+type RustcSpanLine struct {
+	Text string `json:"text"`
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCargoJSONParser(t *testing.T) {
+	const sample = `{"reason":"compiler-artifact","package_id":"path+file:///work/app#0.1.0","target":{"name":"app"},"fresh":false}
+{"reason":"compiler-message","package_id":"path+file:///work/app#0.1.0","message":{"rendered":"warning: unneeded ` + "`return`" + ` statement","$message_type":"diagnostic","children":[{"children":[],"code":null,"level":"help","message":"for further information visit https://rust-lang.github.io/rust-clippy/master/index.html#needless_return","rendered":null,"spans":[]},{"children":[],"code":null,"level":"help","message":"remove ` + "`return`" + `","rendered":null,"spans":[{"byte_end":42,"byte_start":30,"column_end":17,"column_start":5,"expansion":null,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":3,"line_start":3,"suggested_replacement":"\"あ\"","suggestion_applicability":"MachineApplicable","text":[{"highlight_end":17,"highlight_start":5,"text":"    return \"あ\";"}]}]}],"code":{"code":"clippy::needless_return","explanation":null},"level":"warning","message":"unneeded ` + "`return`" + ` statement","spans":[{"byte_end":42,"byte_start":30,"column_end":17,"column_start":5,"expansion":null,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":3,"line_start":3,"suggested_replacement":null,"suggestion_applicability":null,"text":[{"highlight_end":17,"highlight_start":5,"text":"    return \"あ\";"}]}]}}
+{"reason":"compiler-message","package_id":"path+file:///work/app#0.1.0","message":{"rendered":"error[E0308]: mismatched types","$message_type":"diagnostic","children":[{"children":[],"code":null,"level":"note","message":"function defined here","rendered":null,"spans":[{"byte_end":70,"byte_start":67,"column_end":7,"column_start":4,"expansion":null,"file_name":"src/lib.rs","is_primary":true,"label":null,"line_end":5,"line_start":5,"suggested_replacement":null,"suggestion_applicability":null,"text":[]}]},{"children":[],"code":null,"level":"help","message":"try using a conversion method","rendered":null,"spans":[{"byte_end":100,"byte_start":100,"column_end":10,"column_start":10,"expansion":null,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":8,"line_start":8,"suggested_replacement":".to_string()","suggestion_applicability":"MaybeIncorrect","text":[]}]}],"code":{"code":"E0308","explanation":"Expected type did not match the received type.\n"},"level":"error","message":"mismatched types","spans":[{"byte_end":100,"byte_start":95,"column_end":10,"column_start":5,"expansion":null,"file_name":"src/main.rs","is_primary":true,"label":"expected ` + "`String`" + `, found ` + "`&str`" + `","line_end":8,"line_start":8,"suggested_replacement":null,"suggestion_applicability":null,"text":[]},{"byte_end":94,"byte_start":91,"column_end":4,"column_start":1,"expansion":null,"file_name":"src/main.rs","is_primary":false,"label":"arguments to this function are incorrect","line_end":8,"line_start":8,"suggested_replacement":null,"suggestion_applicability":null,"text":[]}]}}
+{"reason":"compiler-message","package_id":"path+file:///work/app#0.1.0","message":{"rendered":"error: aborting due to 1 previous error","$message_type":"diagnostic","children":[],"code":null,"level":"error","message":"aborting due to 1 previous error","spans":[]}}
+{"reason":"build-finished","success":false}
+`
+	ds, err := NewCargoJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "unneeded `return` statement\nhelp: for further information visit https://rust-lang.github.io/rust-clippy/master/index.html#needless_return",
+			Location: &rdf.Location{
+				Path: "src/main.rs",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 3, Column: 5},
+					// "あ" is 3 bytes in UTF-8.
+					End: &rdf.Position{Line: 3, Column: 19},
+				},
+			},
+			Severity: rdf.Severity_WARNING,
+			Code: &rdf.Code{
+				Value: "clippy::needless_return",
+				Url:   "https://rust-lang.github.io/rust-clippy/master/index.html#needless_return",
+			},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 3, Column: 5},
+						End:   &rdf.Position{Line: 3, Column: 19},
+					},
+					Text: `"あ"`,
+				},
+			},
+			OriginalOutput: "warning: unneeded `return` statement",
+		},
+		{
+			Message: "mismatched types",
+			Location: &rdf.Location{
+				Path: "src/main.rs",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 8, Column: 5},
+					End:   &rdf.Position{Line: 8, Column: 10},
+				},
+			},
+			Severity: rdf.Severity_ERROR,
+			Code: &rdf.Code{
+				Value: "E0308",
+				Url:   "https://doc.rust-lang.org/error_codes/E0308.html",
+			},
+			RelatedLocations: []*rdf.RelatedLocation{
+				{
+					Message: "arguments to this function are incorrect",
+					Location: &rdf.Location{
+						Path: "src/main.rs",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 8, Column: 1},
+							End:   &rdf.Position{Line: 8, Column: 4},
+						},
+					},
+				},
+				{
+					Message: "function defined here",
+					Location: &rdf.Location{
+						Path: "src/lib.rs",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 5, Column: 4},
+							End:   &rdf.Position{Line: 5, Column: 7},
+						},
+					},
+				},
+			},
+			OriginalOutput: "error[E0308]: mismatched types",
+		},
+	}
+	if diff := cmp.Diff(want, ds, protocmp.Transform()); diff != "" {
+		t.Errorf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestCargoJSONParser_multiSpanSuggestion(t *testing.T) {
+	const sample = `{"reason":"compiler-message","message":{"rendered":"warning: unnecessary parentheses","children":[{"children":[],"code":null,"level":"help","message":"remove these parentheses","rendered":null,"spans":[{"column_end":20,"column_start":19,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":2,"line_start":2,"suggested_replacement":"","suggestion_applicability":"MachineApplicable","text":[{"text":"    let x = (1 + 2);"}]},{"column_end":14,"column_start":13,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":2,"line_start":2,"suggested_replacement":"","suggestion_applicability":"MachineApplicable","text":[{"text":"    let x = (1 + 2);"}]}]},{"children":[],"code":null,"level":"help","message":"also update the other file","rendered":null,"spans":[{"column_end":14,"column_start":13,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":2,"line_start":2,"suggested_replacement":"","suggestion_applicability":"MachineApplicable","text":[{"text":"    let x = (1 + 2);"}]},{"column_end":1,"column_start":1,"file_name":"src/lib.rs","is_primary":true,"label":null,"line_end":2,"line_start":2,"suggested_replacement":"x","suggestion_applicability":"MachineApplicable","text":[{"text":""}]}]}],"code":{"code":"unused_parens","explanation":null},"level":"warning","message":"unnecessary parentheses around assigned value","spans":[{"column_end":20,"column_start":13,"file_name":"src/main.rs","is_primary":true,"label":null,"line_end":2,"line_start":2,"suggested_replacement":null,"suggestion_applicability":null,"text":[{"text":"    let x = (1 + 2);"}]}]}}`
+	ds, err := NewCargoJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	// Replacements of a child are merged into one suggestion, and a child
+	// which also edits another file is dropped.
+	want := []*rdf.Suggestion{
+		{
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: 2, Column: 13},
+				End:   &rdf.Position{Line: 2, Column: 20},
+			},
+			Text: "1 + 2",
+		},
+	}
+	if diff := cmp.Diff(want, ds[0].GetSuggestions(), protocmp.Transform()); diff != "" {
+		t.Errorf("suggestions (-want +got):\n%s", diff)
+	}
+}
+
+func TestCargoJSONParser_rustc(t *testing.T) {
+	const sample = `{"$message_type":"diagnostic","message":"unused variable: ` + "`x`" + `","code":{"code":"unused_variables","explanation":null},"level":"warning","spans":[{"file_name":"main.rs","byte_start":16,"byte_end":17,"line_start":2,"line_end":2,"column_start":9,"column_end":10,"is_primary":true,"text":[{"text":"    let x = 1;","highlight_start":9,"highlight_end":10}],"label":null,"suggested_replacement":null,"suggestion_applicability":null,"expansion":null}],"children":[{"message":"if this is intentional, prefix it with an underscore","code":null,"level":"help","spans":[{"file_name":"main.rs","byte_start":16,"byte_end":17,"line_start":2,"line_end":2,"column_start":9,"column_end":10,"is_primary":true,"text":[{"text":"    let x = 1;","highlight_start":9,"highlight_end":10}],"label":null,"suggested_replacement":"_x","suggestion_applicability":"MachineApplicable","expansion":null}],"children":[],"rendered":null}],"rendered":"warning: unused variable: ` + "`x`" + `\n"}
+`
+	ds, err := NewCargoJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	want := []*rdf.Suggestion{
+		{
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: 2, Column: 9},
+				End:   &rdf.Position{Line: 2, Column: 10},
+			},
+			Text: "_x",
+		},
+	}
+	if diff := cmp.Diff(want, ds[0].GetSuggestions(), protocmp.Transform()); diff != "" {
+		t.Errorf("suggestions (-want +got):\n%s", diff)
+	}
+	if got := ds[0].GetCode().GetValue(); got != "unused_variables" {
+		t.Errorf("code = %q, want unused_variables", got)
+	}
+}
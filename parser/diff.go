@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"io"
+	"iter"
 	"strings"
 
 	"github.com/reviewdog/reviewdog/diff"
@@ -10,7 +11,7 @@ import (
 	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
-var _ Parser = &DiffParser{}
+var _ StreamParser = &DiffParser{}
 
 // DiffParser is a unified diff parser.
 type DiffParser struct {
@@ -51,75 +52,87 @@ func (d dstate) build(path string, currentLine int) *rdf.Diagnostic {
 
 // Parse parses input as unified diff format and return it as diagnostics.
 func (p *DiffParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
-	filediffs, err := diff.ParseMultiFile(r)
-	if err != nil {
-		return nil, fmt.Errorf("fail to parse diff: %w", err)
+	return collect(p.ParseStream(r))
+}
+
+// ParseStream parses input as unified diff format and yields diagnostics of
+// each file diff as soon as the file diff is parsed.
+func (p *DiffParser) ParseStream(r io.Reader) iter.Seq2[*rdf.Diagnostic, error] {
+	return func(yield func(*rdf.Diagnostic, error) bool) {
+		for fdiff := range diff.ParseMultiFileSeq(r) {
+			for _, d := range p.fileDiagnostics(fdiff) {
+				if !yield(d, nil) {
+					return
+				}
+			}
+		}
 	}
+}
+
+func (p *DiffParser) fileDiagnostics(fdiff *diff.FileDiff) []*rdf.Diagnostic {
 	var diagnostics []*rdf.Diagnostic
-	for _, fdiff := range filediffs {
-		path := pathutil.NormalizeDiffPath(fdiff.PathNew, p.strip)
-		for _, hunk := range fdiff.Hunks {
-			lnum := hunk.StartLineOld - 1
-			prevState := diff.LineUnchanged
-			state := dstate{}
-			emit := func() {
-				diagnostics = append(diagnostics, state.build(path, lnum))
-				state = dstate{}
-			}
-			for i, diffLine := range hunk.Lines {
-				switch diffLine.Type {
+	path := pathutil.NormalizeDiffPath(fdiff.PathNew, p.strip)
+	for _, hunk := range fdiff.Hunks {
+		lnum := hunk.StartLineOld - 1
+		prevState := diff.LineUnchanged
+		state := dstate{}
+		emit := func() {
+			diagnostics = append(diagnostics, state.build(path, lnum))
+			state = dstate{}
+		}
+		for i, diffLine := range hunk.Lines {
+			switch diffLine.Type {
+			case diff.LineAdded:
+				if i == 0 {
+					lnum++ // Increment line number only when it's at head.
+				}
+				state.newLines = append(state.newLines, diffLine.Content)
+				state.originalLines = append(state.originalLines, buildOriginalLine(path, diffLine))
+				switch prevState {
+				case diff.LineUnchanged:
+					// Insert.
+					state.startLine = lnum + 1
+					state.isInsert = true
+				case diff.LineDeleted, diff.LineAdded:
+					// Do nothing in particular.
+				}
+			case diff.LineDeleted:
+				lnum++
+				state.originalLines = append(state.originalLines, buildOriginalLine(path, diffLine))
+				switch prevState {
+				case diff.LineUnchanged:
+					state.startLine = lnum
 				case diff.LineAdded:
-					if i == 0 {
-						lnum++ // Increment line number only when it's at head.
-					}
-					state.newLines = append(state.newLines, diffLine.Content)
-					state.originalLines = append(state.originalLines, buildOriginalLine(path, diffLine))
-					switch prevState {
-					case diff.LineUnchanged:
-						// Insert.
-						state.startLine = lnum + 1
-						state.isInsert = true
-					case diff.LineDeleted, diff.LineAdded:
-						// Do nothing in particular.
-					}
+					state.isInsert = false
 				case diff.LineDeleted:
-					lnum++
-					state.originalLines = append(state.originalLines, buildOriginalLine(path, diffLine))
-					switch prevState {
-					case diff.LineUnchanged:
-						state.startLine = lnum
-					case diff.LineAdded:
-						state.isInsert = false
-					case diff.LineDeleted:
-						// Do nothing in particular.
-					}
+					// Do nothing in particular.
+				}
+			case diff.LineUnchanged:
+				switch prevState {
 				case diff.LineUnchanged:
-					switch prevState {
-					case diff.LineUnchanged:
-						// Do nothing in particular.
-					case diff.LineAdded, diff.LineDeleted:
-						emit() // Output a diagnostic.
-					}
-					lnum++
+					// Do nothing in particular.
+				case diff.LineAdded, diff.LineDeleted:
+					emit() // Output a diagnostic.
 				}
-				prevState = diffLine.Type
-			}
-			if hunk.EOFNewline == diff.LineAdded {
-				// Adding a blank line here should prompt an EOF newline
-				// to be inserted (rather than a complete blank line).
-				// This is known to work with GitHub review suggestions, at least.
-				// See https://github.com/reviewdog/reviewdog/pull/1975#issuecomment-2634826110
-				// It hasn't yet been tested with other reporters.
-				state.newLines = append(state.newLines, "")
-				// NOTE: this doesn't handle the case of a deleted eof newline
-				// because it's much rarer in practice.
-			}
-			if state.startLine > 0 {
-				emit() // Output a diagnostic at the end of hunk.
+				lnum++
 			}
+			prevState = diffLine.Type
+		}
+		if hunk.EOFNewline == diff.LineAdded {
+			// Adding a blank line here should prompt an EOF newline
+			// to be inserted (rather than a complete blank line).
+			// This is known to work with GitHub review suggestions, at least.
+			// See https://github.com/reviewdog/reviewdog/pull/1975#issuecomment-2634826110
+			// It hasn't yet been tested with other reporters.
+			state.newLines = append(state.newLines, "")
+			// NOTE: this doesn't handle the case of a deleted eof newline
+			// because it's much rarer in practice.
+		}
+		if state.startLine > 0 {
+			emit() // Output a diagnostic at the end of hunk.
 		}
 	}
-	return diagnostics, nil
+	return diagnostics
 }
 
 func buildOriginalLine(path string, line *diff.Line) string {
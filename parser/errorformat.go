@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"io"
+	"iter"
 	"strings"
 
 	"github.com/reviewdog/errorformat"
@@ -10,7 +11,7 @@ import (
 	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
-var _ Parser = &ErrorformatParser{}
+var _ StreamParser = &ErrorformatParser{}
 
 // ErrorformatParser is errorformat parser.
 type ErrorformatParser struct {
@@ -33,11 +34,19 @@ func NewErrorformatParserString(efms []string) (*ErrorformatParser, error) {
 }
 
 func (p *ErrorformatParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
-	s := p.efm.NewScanner(r)
-	var ds []*rdf.Diagnostic
-	for s.Scan() {
-		e := s.Entry()
-		if e.Valid {
+	return collect(p.ParseStream(r))
+}
+
+// ParseStream parses r line by line and yields a diagnostic per valid
+// errorformat entry.
+func (p *ErrorformatParser) ParseStream(r io.Reader) iter.Seq2[*rdf.Diagnostic, error] {
+	return func(yield func(*rdf.Diagnostic, error) bool) {
+		s := p.efm.NewScanner(r)
+		for s.Scan() {
+			e := s.Entry()
+			if !e.Valid {
+				continue
+			}
 			d := &rdf.Diagnostic{
 				Location: &rdf.Location{
 					Path: e.Filename,
@@ -55,8 +64,9 @@ func (p *ErrorformatParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 			if e.Nr != 0 {
 				d.Code = &rdf.Code{Value: fmt.Sprintf("%d", e.Nr)}
 			}
-			ds = append(ds, d)
+			if !yield(d, nil) {
+				return
+			}
 		}
 	}
-	return ds, nil
 }
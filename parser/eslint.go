@@ -0,0 +1,268 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &ESLintJSONParser{}
+
+// ESLintJSONParser is parser for ESLint JSON formatters (eslint -f json and
+// eslint -f json-with-metadata).
+//
+// Both fixes and suggestions are converted to suggestions. ESLint columns and
+// fix ranges are UTF-16 code unit offsets of the source text, so it reads the
+// source files (or uses the source in the output if any) to convert them to
+// byte based columns.
+type ESLintJSONParser struct{}
+
+// NewESLintJSONParser returns a new ESLintJSONParser.
+func NewESLintJSONParser() *ESLintJSONParser {
+	return &ESLintJSONParser{}
+}
+
+func (p *ESLintJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var out ESLintJSONWithMetadata
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		err = json.Unmarshal(b, &out.Results)
+	} else {
+		err = json.Unmarshal(b, &out)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ESLint JSON output: %w", err)
+	}
+	var ds []*rdf.Diagnostic
+	for _, result := range out.Results {
+		src := newESLintSource(result)
+		for _, msg := range result.Messages {
+			ds = append(ds, msg.toRDF(result.FilePath, src, out.Metadata))
+		}
+	}
+	return ds, nil
+}
+
+func (msg *ESLintMessage) toRDF(path string, src *eslintSource, meta *ESLintMetadata) *rdf.Diagnostic {
+	original, _ := json.Marshal(msg)
+	d := &rdf.Diagnostic{
+		Message: msg.Message,
+		Location: &rdf.Location{
+			Path: path,
+		},
+		Severity:       eslintSeverity(msg.Severity),
+		OriginalOutput: string(original),
+	}
+	if msg.Line > 0 {
+		d.Location.Range = &rdf.Range{
+			Start: src.position(msg.Line, msg.Column),
+		}
+		if msg.EndLine > 0 {
+			d.Location.Range.End = src.position(msg.EndLine, msg.EndColumn)
+		}
+	}
+	if msg.RuleID != "" {
+		d.Code = &rdf.Code{Value: msg.RuleID}
+		if meta != nil {
+			d.Code.Url = meta.RulesMeta[msg.RuleID].Docs.URL
+		}
+	}
+	fixes := make([]*ESLintFix, 0, len(msg.Suggestions)+1)
+	if msg.Fix != nil {
+		fixes = append(fixes, msg.Fix)
+	}
+	for _, s := range msg.Suggestions {
+		if s.Fix != nil {
+			fixes = append(fixes, s.Fix)
+		}
+	}
+	for _, fix := range fixes {
+		if rng := src.rangeOf(fix.Range); rng != nil {
+			d.Suggestions = append(d.Suggestions, &rdf.Suggestion{
+				Range: rng,
+				Text:  fix.Text,
+			})
+		}
+	}
+	return d
+}
+
+func eslintSeverity(s int) rdf.Severity {
+	switch s {
+	case 2:
+		return rdf.Severity_ERROR
+	case 1:
+		return rdf.Severity_WARNING
+	}
+	return rdf.Severity_UNKNOWN_SEVERITY
+}
+
+// eslintSource converts UTF-16 based columns and offsets of ESLint to byte
+// based positions.
+type eslintSource struct {
+	text []byte
+	// lines holds byte offsets of the beginning of each line.
+	lines []int
+	// lines16 holds UTF-16 offsets of the beginning of each line.
+	lines16 []int
+}
+
+func newESLintSource(result *ESLintResult) *eslintSource {
+	var text []byte
+	if result.Source != nil {
+		text = []byte(*result.Source)
+	} else if b, err := os.ReadFile(result.FilePath); err == nil {
+		text = b
+	} else {
+		// Columns are used as they are and fixes are ignored.
+		return nil
+	}
+	// ESLint ranges are offsets of the text without BOM.
+	text = bytes.TrimPrefix(text, []byte("\ufeff"))
+	src := &eslintSource{text: text, lines: []int{0}, lines16: []int{0}}
+	n16 := 0
+	for i, r := range string(text) {
+		n16 += utf16.RuneLen(r)
+		if isESLintLineBreak(text, i, r) {
+			src.lines = append(src.lines, i+utf8.RuneLen(r))
+			src.lines16 = append(src.lines16, n16)
+		}
+	}
+	return src
+}
+
+// isESLintLineBreak reports whether the rune at i ends a line. ESLint treats
+// \r\n, \r, \n, \u2028 and \u2029 as line breaks.
+func isESLintLineBreak(text []byte, i int, r rune) bool {
+	switch r {
+	case '\n', '\u2028', '\u2029':
+		return true
+	case '\r':
+		return i+1 >= len(text) || text[i+1] != '\n'
+	}
+	return false
+}
+
+// position returns the position of 1-origin line and UTF-16 based column.
+func (src *eslintSource) position(line, column int) *rdf.Position {
+	pos := &rdf.Position{Line: int32(line), Column: int32(column)}
+	if src == nil || line > len(src.lines) {
+		return pos
+	}
+	start := src.lines[line-1]
+	if col, ok := src.byteColumn(start, column-1); ok {
+		pos.Column = int32(col)
+	}
+	return pos
+}
+
+// rangeOf returns the range of [start, end) UTF-16 offsets.
+func (src *eslintSource) rangeOf(r [2]int) *rdf.Range {
+	if src == nil || r[0] < 0 || r[1] < r[0] {
+		return nil
+	}
+	start, ok := src.offsetPosition(r[0])
+	if !ok {
+		return nil
+	}
+	end, ok := src.offsetPosition(r[1])
+	if !ok {
+		return nil
+	}
+	return &rdf.Range{Start: start, End: end}
+}
+
+func (src *eslintSource) offsetPosition(offset16 int) (*rdf.Position, bool) {
+	// The last line which begins at or before the offset.
+	i := sort.SearchInts(src.lines16, offset16+1) - 1
+	col, ok := src.byteColumn(src.lines[i], offset16-src.lines16[i])
+	if !ok {
+		return nil, false
+	}
+	return &rdf.Position{Line: int32(i + 1), Column: int32(col)}, true
+}
+
+// byteColumn returns 1-origin byte column of the position which is n UTF-16
+// code units after the beginning of the line at the given byte offset.
+func (src *eslintSource) byteColumn(lineStart, n int) (int, bool) {
+	i := lineStart
+	for n > 0 {
+		if i >= len(src.text) {
+			return 0, false
+		}
+		r, size := utf8.DecodeRune(src.text[i:])
+		n -= utf16.RuneLen(r)
+		i += size
+	}
+	if n < 0 {
+		// The offset points to the middle of a surrogate pair.
+		return 0, false
+	}
+	return i - lineStart + 1, true
+}
+
+// ESLintJSONWithMetadata represents output of eslint -f json-with-metadata.
+// Output of eslint -f json is Results only.
+//
+// References:
+//   - https://eslint.org/docs/latest/use/formatters/#json
+//   - https://eslint.org/docs/latest/use/formatters/#json-with-metadata
+type ESLintJSONWithMetadata struct {
+	Results  []*ESLintResult `json:"results"`
+	Metadata *ESLintMetadata `json:"metadata"`
+}
+
+// ESLintResult represents lint results of a file.
+type ESLintResult struct {
+	FilePath string           `json:"filePath"`
+	Messages []*ESLintMessage `json:"messages"`
+	// Source is the source text of the file. It's available only if the file
+	// has problems and it's not fixed.
+	Source *string `json:"source,omitempty"`
+}
+
+// ESLintMessage represents a problem. Lines and columns are 1-origin and
+// columns are UTF-16 based. The end column is exclusive.
+type ESLintMessage struct {
+	RuleID      string              `json:"ruleId"`
+	Severity    int                 `json:"severity"`
+	Message     string              `json:"message"`
+	Line        int                 `json:"line"`
+	Column      int                 `json:"column"`
+	EndLine     int                 `json:"endLine,omitempty"`
+	EndColumn   int                 `json:"endColumn,omitempty"`
+	Fix         *ESLintFix          `json:"fix,omitempty"`
+	Suggestions []*ESLintSuggestion `json:"suggestions,omitempty"`
+}
+
+// ESLintFix represents a fix which replaces [range[0], range[1]) UTF-16
+// offsets of the source text with the text.
+type ESLintFix struct {
+	Range [2]int `json:"range"`
+	Text  string `json:"text"`
+}
+
+// ESLintSuggestion represents a suggestion.
+type ESLintSuggestion struct {
+	Desc string     `json:"desc"`
+	Fix  *ESLintFix `json:"fix"`
+}
+
+// ESLintMetadata represents metadata of eslint -f json-with-metadata.
+type ESLintMetadata struct {
+	RulesMeta map[string]struct {
+		Docs struct {
+			URL string `json:"url"`
+		} `json:"docs"`
+	} `json:"rulesMeta"`
+}
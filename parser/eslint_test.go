@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestESLintJSONParser(t *testing.T) {
+	// "😀" is 2 UTF-16 code units and 4 bytes, "あ" is 1 UTF-16 code unit and
+	// 3 bytes.
+	const src = "const s = '😀あ'\r\nvar x = 1;\n"
+	file := filepath.Join(t.TempDir(), "a.js")
+	if err := os.WriteFile(file, []byte("\ufeff"+src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fileJSON, _ := json.Marshal(file)
+	sample := fmt.Sprintf(`[
+	{
+		"filePath": %s,
+		"messages": [
+			{
+				"ruleId": "semi",
+				"severity": 2,
+				"message": "Missing semicolon.",
+				"line": 1,
+				"column": 16,
+				"endLine": 2,
+				"endColumn": 1,
+				"fix": {"range": [15, 15], "text": ";"}
+			},
+			{
+				"ruleId": "no-var",
+				"severity": 1,
+				"message": "Unexpected var, use let or const instead.",
+				"line": 2,
+				"column": 1,
+				"endLine": 2,
+				"endColumn": 11,
+				"suggestions": [
+					{"desc": "Use let.", "fix": {"range": [17, 20], "text": "let"}},
+					{"desc": "Use const.", "fix": {"range": [17, 20], "text": "const"}}
+				]
+			}
+		],
+		"errorCount": 1,
+		"warningCount": 1
+	},
+	{
+		"filePath": "/does/not/exist.js",
+		"messages": [
+			{
+				"ruleId": null,
+				"fatal": true,
+				"severity": 2,
+				"message": "Parsing error: Unexpected token",
+				"line": 3,
+				"column": 5,
+				"fix": {"range": [0, 1], "text": ""}
+			}
+		]
+	}
+]`, fileJSON)
+	ds, err := NewESLintJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if d.GetOriginalOutput() == "" {
+			t.Errorf("empty original output: %v", d)
+		}
+		d.OriginalOutput = ""
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "Missing semicolon.",
+			Location: &rdf.Location{
+				Path: file,
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 1, Column: 20},
+					End:   &rdf.Position{Line: 2, Column: 1},
+				},
+			},
+			Severity: rdf.Severity_ERROR,
+			Code:     &rdf.Code{Value: "semi"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 1, Column: 20},
+						End:   &rdf.Position{Line: 1, Column: 20},
+					},
+					Text: ";",
+				},
+			},
+		},
+		{
+			Message: "Unexpected var, use let or const instead.",
+			Location: &rdf.Location{
+				Path: file,
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 2, Column: 1},
+					End:   &rdf.Position{Line: 2, Column: 11},
+				},
+			},
+			Severity: rdf.Severity_WARNING,
+			Code:     &rdf.Code{Value: "no-var"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 2, Column: 1},
+						End:   &rdf.Position{Line: 2, Column: 4},
+					},
+					Text: "let",
+				},
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 2, Column: 1},
+						End:   &rdf.Position{Line: 2, Column: 4},
+					},
+					Text: "const",
+				},
+			},
+		},
+		{
+			Message: "Parsing error: Unexpected token",
+			Location: &rdf.Location{
+				Path:  "/does/not/exist.js",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 5}},
+			},
+			Severity: rdf.Severity_ERROR,
+		},
+	}
+	if diff := cmp.Diff(want, ds, protocmp.Transform()); diff != "" {
+		t.Errorf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestESLintJSONParser_withMetadata(t *testing.T) {
+	const sample = `{
+	"results": [
+		{
+			"filePath": "/does/not/exist.js",
+			"source": "let s = 'ä'\n",
+			"messages": [
+				{
+					"ruleId": "quotes",
+					"severity": 2,
+					"message": "Strings must use doublequote.",
+					"line": 1,
+					"column": 9,
+					"endLine": 1,
+					"endColumn": 12,
+					"fix": {"range": [8, 11], "text": "\"ä\""}
+				}
+			]
+		}
+	],
+	"metadata": {
+		"rulesMeta": {
+			"quotes": {"type": "layout", "docs": {"description": "Enforce quotes", "url": "https://eslint.org/docs/latest/rules/quotes"}}
+		}
+	}
+}`
+	ds, err := NewESLintJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	if diff := cmp.Diff(&rdf.Code{Value: "quotes", Url: "https://eslint.org/docs/latest/rules/quotes"}, ds[0].GetCode(), protocmp.Transform()); diff != "" {
+		t.Errorf("code (-want +got):\n%s", diff)
+	}
+	wantRange := &rdf.Range{
+		Start: &rdf.Position{Line: 1, Column: 9},
+		End:   &rdf.Position{Line: 1, Column: 13},
+	}
+	if diff := cmp.Diff(wantRange, ds[0].GetLocation().GetRange(), protocmp.Transform()); diff != "" {
+		t.Errorf("range (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantRange, ds[0].GetSuggestions()[0].GetRange(), protocmp.Transform()); diff != "" {
+		t.Errorf("suggestion range (-want +got):\n%s", diff)
+	}
+}
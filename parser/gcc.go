@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &GCCJSONParser{}
+
+// GCCJSONParser is parser for GCC JSON diagnostics format
+// (-fdiagnostics-format=json).
+//
+// Fix-it hints of a diagnostic are merged into a suggestion, and notes (child
+// diagnostics) are converted to related locations. Events of diagnostic paths (e.g.
+// -fanalyzer) are converted to an ordered trace of related locations.
+type GCCJSONParser struct{}
+
+// NewGCCJSONParser returns a new GCCJSONParser.
+func NewGCCJSONParser() *GCCJSONParser {
+	return &GCCJSONParser{}
+}
+
+// Parse parses GCC JSON diagnostics. The input can be multiple JSON arrays,
+// e.g. outputs of multiple compiler invocations.
+func (p *GCCJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	files := &offsetConverter{}
+	dec := json.NewDecoder(r)
+	for {
+		var diags []*GCCDiagnostic
+		if err := dec.Decode(&diags); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to unmarshal GCC JSON diagnostics: %w", err)
+		}
+		for _, gd := range diags {
+			d, err := gd.toRDF(files)
+			if err != nil {
+				return nil, err
+			}
+			if d != nil {
+				ds = append(ds, d)
+			}
+		}
+	}
+	return ds, nil
+}
+
+func (gd *GCCDiagnostic) toRDF(files *offsetConverter) (*rdf.Diagnostic, error) {
+	if len(gd.Locations) == 0 || gd.Locations[0].Caret == nil {
+		// e.g. "fatal error: no input files".
+		return nil, nil
+	}
+	original, err := json.Marshal(gd)
+	if err != nil {
+		return nil, err
+	}
+	loc := gd.Locations[0].toRDF()
+	d := &rdf.Diagnostic{
+		Message:        gd.Message,
+		Location:       loc,
+		Severity:       gccSeverity(gd.Kind),
+		OriginalOutput: string(original),
+	}
+	if gd.Option != "" {
+		d.Code = &rdf.Code{Value: gd.Option, Url: gd.OptionURL}
+	}
+	if s := gd.suggestion(loc.GetPath(), files); s != nil {
+		d.Suggestions = append(d.Suggestions, s)
+	}
+	for _, child := range gd.Children {
+		if len(child.Locations) == 0 {
+			continue
+		}
+		d.RelatedLocations = append(d.RelatedLocations, &rdf.RelatedLocation{
+			Message:  child.Message,
+			Location: child.Locations[0].toRDF(),
+		})
+		if s := child.suggestion(loc.GetPath(), files); s != nil {
+			d.Suggestions = append(d.Suggestions, s)
+		}
+	}
+	step := int32(0)
+	for _, event := range gd.Path {
+		if event.Location == nil {
+			continue
+		}
+		step++
+		d.RelatedLocations = append(d.RelatedLocations, &rdf.RelatedLocation{
+			Message:  event.Description,
+			Location: &rdf.Location{Path: event.Location.File, Range: &rdf.Range{Start: event.Location.toRDF()}},
+			Step:     step,
+		})
+	}
+	return d, nil
+}
+
+// suggestion merges fix-it hints into a suggestion for the given path. It
+// returns nil if the hints can't be applied as a whole: a hint is in another
+// file, or multiple hints aren't on the same line or overlap. As the text
+// between hints is needed, it reads the source file to merge multiple hints.
+func (gd *GCCDiagnostic) suggestion(path string, files *offsetConverter) *rdf.Suggestion {
+	if len(gd.Fixits) == 0 {
+		return nil
+	}
+	for _, fixit := range gd.Fixits {
+		if fixit.Start == nil || fixit.Next == nil || fixit.Start.File != path {
+			return nil
+		}
+	}
+	if len(gd.Fixits) == 1 {
+		fixit := gd.Fixits[0]
+		return &rdf.Suggestion{
+			Range: &rdf.Range{
+				Start: fixit.Start.toRDF(),
+				// "next" is the position just after the range (exclusive).
+				End: fixit.Next.toRDF(),
+			},
+			Text: fixit.String,
+		}
+	}
+	fixits := slices.Clone(gd.Fixits)
+	slices.SortStableFunc(fixits, func(a, b *GCCFixit) int {
+		return cmp.Compare(a.Start.toRDF().GetColumn(), b.Start.toRDF().GetColumn())
+	})
+	line := fixits[0].Start.Line
+	src, err := files.line(path, line)
+	if err != nil {
+		return nil
+	}
+	var rng *rdf.Range
+	var text strings.Builder
+	for _, fixit := range fixits {
+		start, end := fixit.Start.toRDF(), fixit.Next.toRDF()
+		if fixit.Start.Line != line || fixit.Next.Line != line || end.GetColumn() < start.GetColumn() {
+			return nil
+		}
+		if rng == nil {
+			rng = &rdf.Range{Start: start}
+		} else {
+			from, to := int(rng.GetEnd().GetColumn())-1, int(start.GetColumn())-1
+			if from > to || to > len(src) {
+				return nil
+			}
+			text.WriteString(src[from:to])
+		}
+		text.WriteString(fixit.String)
+		rng.End = end
+	}
+	return &rdf.Suggestion{Range: rng, Text: text.String()}
+}
+
+func (l *GCCLocation) toRDF() *rdf.Location {
+	start := l.Caret
+	if l.Start != nil {
+		start = l.Start
+	}
+	if start == nil {
+		return &rdf.Location{}
+	}
+	loc := &rdf.Location{
+		Path:  start.File,
+		Range: &rdf.Range{Start: start.toRDF()},
+	}
+	if l.Finish != nil {
+		// "finish" is the last character of the range (inclusive).
+		end := l.Finish.toRDF()
+		end.Column++
+		loc.Range.End = end
+	}
+	return loc
+}
+
+func (p *GCCPosition) toRDF() *rdf.Position {
+	// rdf column is byte based. "column" is a display column since GCC 11
+	// while "byte-column" is available.
+	column := p.Column
+	if p.ByteColumn > 0 {
+		column = p.ByteColumn
+	}
+	return &rdf.Position{
+		Line:   int32(p.Line),
+		Column: int32(column),
+	}
+}
+
+func gccSeverity(kind string) rdf.Severity {
+	switch kind {
+	case "error", "fatal error", "sorry, unimplemented", "internal compiler error":
+		return rdf.Severity_ERROR
+	case "warning", "pedwarn", "permerror":
+		return rdf.Severity_WARNING
+	case "note", "remark":
+		return rdf.Severity_INFO
+	}
+	return severity(kind)
+}
+
+// GCCDiagnostic represents a diagnostic of GCC JSON diagnostics format.
+//
+// References:
+//   - https://gcc.gnu.org/onlinedocs/gcc/Diagnostic-Message-Formatting-Options.html
+type GCCDiagnostic struct {
+	Kind      string           `json:"kind"`
+	Message   string           `json:"message"`
+	Option    string           `json:"option,omitempty"`
+	OptionURL string           `json:"option_url,omitempty"`
+	Locations []*GCCLocation   `json:"locations"`
+	Fixits    []*GCCFixit      `json:"fixits,omitempty"`
+	Children  []*GCCDiagnostic `json:"children,omitempty"`
+	Path      []*GCCPathEvent  `json:"path,omitempty"`
+}
+
+// GCCLocation represents a location with a caret and an optional range.
+type GCCLocation struct {
+	Caret  *GCCPosition `json:"caret"`
+	Start  *GCCPosition `json:"start,omitempty"`
+	Finish *GCCPosition `json:"finish,omitempty"`
+	Label  string       `json:"label,omitempty"`
+}
+
+// GCCPosition represents a position in a file. Line and column are 1-origin.
+type GCCPosition struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	ByteColumn int    `json:"byte-column,omitempty"`
+}
+
+// GCCFixit represents a fix-it hint which replaces text in [start, next) with
+// string.
+type GCCFixit struct {
+	Start  *GCCPosition `json:"start"`
+	Next   *GCCPosition `json:"next"`
+	String string       `json:"string"`
+}
+
+// GCCPathEvent represents an event of a diagnostic path.
+type GCCPathEvent struct {
+	Location    *GCCPosition `json:"location"`
+	Description string       `json:"description"`
+	Function    string       `json:"function,omitempty"`
+	Depth       int          `json:"depth,omitempty"`
+}
@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestGCCJSONParser(t *testing.T) {
+	// Output of two compiler invocations.
+	const sample = `[
+	{
+		"kind": "warning",
+		"message": "unused variable 'x'",
+		"option": "-Wunused-variable",
+		"option_url": "https://gcc.gnu.org/onlinedocs/gcc/Warning-Options.html#index-Wunused-variable",
+		"locations": [
+			{
+				"caret": {"file": "main.c", "line": 3, "display-column": 7, "byte-column": 7, "column": 7},
+				"finish": {"file": "main.c", "line": 3, "display-column": 7, "byte-column": 7, "column": 7}
+			}
+		],
+		"children": [],
+		"column-origin": 1,
+		"escape-source": false
+	},
+	{
+		"kind": "error",
+		"message": "'fo' undeclared (first use in this function); did you mean 'foo'?",
+		"locations": [
+			{
+				"caret": {"file": "main.c", "line": 5, "display-column": 8, "byte-column": 10, "column": 8},
+				"finish": {"file": "main.c", "line": 5, "display-column": 9, "byte-column": 11, "column": 9}
+			}
+		],
+		"fixits": [
+			{
+				"start": {"file": "main.c", "line": 5, "display-column": 8, "byte-column": 10, "column": 8},
+				"next": {"file": "main.c", "line": 5, "display-column": 10, "byte-column": 12, "column": 10},
+				"string": "foo"
+			}
+		],
+		"children": [
+			{
+				"kind": "note",
+				"message": "'foo' declared here",
+				"locations": [
+					{
+						"caret": {"file": "main.c", "line": 2, "column": 5}
+					}
+				]
+			}
+		]
+	}
+]
+[
+	{
+		"kind": "fatal error",
+		"message": "no input files",
+		"locations": []
+	},
+	{
+		"kind": "warning",
+		"message": "leak of 'p'",
+		"option": "-Wanalyzer-malloc-leak",
+		"locations": [
+			{
+				"caret": {"file": "leak.c", "line": 6, "column": 1}
+			}
+		],
+		"path": [
+			{
+				"location": {"file": "leak.c", "line": 4, "column": 13},
+				"description": "allocated here",
+				"function": "f",
+				"depth": 0
+			},
+			{
+				"location": {"file": "leak.c", "line": 6, "column": 1},
+				"description": "'p' leaks here; was allocated at (1)",
+				"function": "f",
+				"depth": 0
+			}
+		]
+	}
+]
+`
+	ds, err := NewGCCJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if d.GetOriginalOutput() == "" {
+			t.Errorf("empty original output: %v", d)
+		}
+		d.OriginalOutput = ""
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "unused variable 'x'",
+			Location: &rdf.Location{
+				Path: "main.c",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 3, Column: 7},
+					End:   &rdf.Position{Line: 3, Column: 8},
+				},
+			},
+			Severity: rdf.Severity_WARNING,
+			Code: &rdf.Code{
+				Value: "-Wunused-variable",
+				Url:   "https://gcc.gnu.org/onlinedocs/gcc/Warning-Options.html#index-Wunused-variable",
+			},
+		},
+		{
+			Message: "'fo' undeclared (first use in this function); did you mean 'foo'?",
+			Location: &rdf.Location{
+				Path: "main.c",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 5, Column: 10},
+					End:   &rdf.Position{Line: 5, Column: 12},
+				},
+			},
+			Severity: rdf.Severity_ERROR,
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 5, Column: 10},
+						End:   &rdf.Position{Line: 5, Column: 12},
+					},
+					Text: "foo",
+				},
+			},
+			RelatedLocations: []*rdf.RelatedLocation{
+				{
+					Message: "'foo' declared here",
+					Location: &rdf.Location{
+						Path:  "main.c",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 2, Column: 5}},
+					},
+				},
+			},
+		},
+		{
+			Message: "leak of 'p'",
+			Location: &rdf.Location{
+				Path:  "leak.c",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 6, Column: 1}},
+			},
+			Severity: rdf.Severity_WARNING,
+			Code:     &rdf.Code{Value: "-Wanalyzer-malloc-leak"},
+			RelatedLocations: []*rdf.RelatedLocation{
+				{
+					Message: "allocated here",
+					Location: &rdf.Location{
+						Path:  "leak.c",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 4, Column: 13}},
+					},
+					Step: 1,
+				},
+				{
+					Message: "'p' leaks here; was allocated at (1)",
+					Location: &rdf.Location{
+						Path:  "leak.c",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 6, Column: 1}},
+					},
+					Step: 2,
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, ds, protocmp.Transform()); diff != "" {
+		t.Errorf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestGCCJSONParser_multiPartFixit(t *testing.T) {
+	const src = `int f(int x) {
+  if (x = 1)
+    return 1;
+  return 0;
+}
+`
+	file := filepath.Join(t.TempDir(), "main.c")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pos := func(line, column int) string {
+		return fmt.Sprintf(`{"file": %q, "line": %d, "column": %d}`, file, line, column)
+	}
+	sample := fmt.Sprintf(`[
+	{
+		"kind": "warning",
+		"message": "suggest parentheses around assignment used as truth value",
+		"locations": [{"caret": %[1]s}],
+		"fixits": [
+			{"start": %[2]s, "next": %[2]s, "string": ")"},
+			{"start": %[1]s, "next": %[1]s, "string": "("}
+		]
+	},
+	{
+		"kind": "warning",
+		"message": "fix-it hints on multiple lines",
+		"locations": [{"caret": %[1]s}],
+		"fixits": [
+			{"start": %[1]s, "next": %[1]s, "string": "("},
+			{"start": %[3]s, "next": %[3]s, "string": ")"}
+		]
+	}
+]`, pos(2, 7), pos(2, 12), pos(3, 13))
+	ds, err := NewGCCJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(ds))
+	}
+	// Parts of a fix-it are merged into a suggestion.
+	want := []*rdf.Suggestion{
+		{
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: 2, Column: 7},
+				End:   &rdf.Position{Line: 2, Column: 12},
+			},
+			Text: "(x = 1)",
+		},
+	}
+	if diff := cmp.Diff(want, ds[0].GetSuggestions(), protocmp.Transform()); diff != "" {
+		t.Errorf("suggestions (-want +got):\n%s", diff)
+	}
+	if got := ds[1].GetSuggestions(); len(got) != 0 {
+		t.Errorf("want no suggestions for fix-it hints on multiple lines, got %v", got)
+	}
+}
+
+func TestGCCJSONParser_error(t *testing.T) {
+	if _, err := NewGCCJSONParser().Parse(strings.NewReader(`main.c:1:1: error: not json`)); err == nil {
+		t.Error("want error")
+	}
+}
@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &GoVetJSONParser{}
+
+// GoVetJSONParser is parser for JSON output of go vet (go vet -json) and
+// analyzers built with golang.org/x/tools/go/analysis (e.g. unitchecker,
+// singlechecker and multichecker with -json flag).
+//
+// Analyzer names are converted to codes. Each suggested fix is converted to a
+// suggestion which merges its text edits, and fixes which also edit other
+// files are dropped. As edits have byte offsets, it reads the source files to
+// convert the offsets to lines and columns.
+type GoVetJSONParser struct{}
+
+// NewGoVetJSONParser returns a new GoVetJSONParser.
+func NewGoVetJSONParser() *GoVetJSONParser {
+	return &GoVetJSONParser{}
+}
+
+// Parse parses go vet -json output. It consists of JSON objects per package,
+// each of which is preceded by a "# <package>" comment line.
+func (p *GoVetJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var buf bytes.Buffer
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024*64)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), "#") {
+			continue
+		}
+		buf.Write(s.Bytes())
+		buf.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	files := &offsetConverter{}
+	var ds []*rdf.Diagnostic
+	dec := json.NewDecoder(&buf)
+	for {
+		// package ID -> analyzer name -> diagnostics or error.
+		var tree map[string]map[string]json.RawMessage
+		if err := dec.Decode(&tree); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to unmarshal go vet JSON output: %w", err)
+		}
+		for _, pkg := range slices.Sorted(maps.Keys(tree)) {
+			for _, analyzer := range slices.Sorted(maps.Keys(tree[pkg])) {
+				raw := tree[pkg][analyzer]
+				var diags []*GoVetDiagnostic
+				if err := json.Unmarshal(raw, &diags); err != nil {
+					// {"error": "..."} if the analyzer failed.
+					continue
+				}
+				for _, vd := range diags {
+					ds = append(ds, vd.toRDF(analyzer, files))
+				}
+			}
+		}
+	}
+	return ds, nil
+}
+
+func (vd *GoVetDiagnostic) toRDF(analyzer string, files *offsetConverter) *rdf.Diagnostic {
+	original, _ := json.Marshal(vd)
+	loc := goVetLocation(vd.Posn, vd.End)
+	d := &rdf.Diagnostic{
+		Message:        vd.Message,
+		Location:       loc,
+		Code:           &rdf.Code{Value: analyzer},
+		OriginalOutput: string(original),
+	}
+	for _, fix := range vd.SuggestedFixes {
+		// Source files may not be available (e.g. reviewdog runs in another
+		// directory). Report the diagnostic without the fix then.
+		if s := files.suggestion(loc.GetPath(), fix.Edits); s != nil {
+			d.Suggestions = append(d.Suggestions, s)
+		}
+	}
+	for _, rel := range vd.Related {
+		d.RelatedLocations = append(d.RelatedLocations, &rdf.RelatedLocation{
+			Message:  rel.Message,
+			Location: goVetLocation(rel.Posn, rel.End),
+		})
+	}
+	return d
+}
+
+// goVetLocation parses positions in "file:line:column" or "file:line" form.
+func goVetLocation(posn, end string) *rdf.Location {
+	path, start := parseGoVetPosn(posn)
+	loc := &rdf.Location{Path: path}
+	if start == nil {
+		return loc
+	}
+	loc.Range = &rdf.Range{Start: start}
+	if endPath, endPos := parseGoVetPosn(end); endPos != nil && endPath == path {
+		loc.Range.End = endPos
+	}
+	return loc
+}
+
+func parseGoVetPosn(posn string) (string, *rdf.Position) {
+	// Parse from the end as file names may contain colons (e.g. C:\foo.go).
+	path, last, ok := cutLastNumber(posn)
+	if !ok {
+		return posn, nil
+	}
+	if p, line, ok := cutLastNumber(path); ok {
+		return p, &rdf.Position{Line: int32(line), Column: int32(last)}
+	}
+	return path, &rdf.Position{Line: int32(last)}
+}
+
+func cutLastNumber(s string) (string, int, bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return s, 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0, false
+	}
+	return s[:i], n, true
+}
+
+// offsetConverter converts byte offsets in files to positions.
+type offsetConverter struct {
+	// lines holds the offsets of the beginning of each line per file.
+	lines map[string][]int
+	// contents holds the contents of each file.
+	contents map[string][]byte
+}
+
+// suggestion merges the edits of a suggested fix into a suggestion which
+// replaces the range from the first edit to the last one. It returns nil if
+// the fix can't be applied as a whole: an edit is out of the file, the edits
+// overlap, or the file isn't readable.
+func (c *offsetConverter) suggestion(filename string, edits []*GoVetTextEdit) *rdf.Suggestion {
+	if len(edits) == 0 {
+		return nil
+	}
+	edits = slices.Clone(edits)
+	slices.SortStableFunc(edits, func(a, b *GoVetTextEdit) int { return cmp.Compare(a.Start, b.Start) })
+	for i, edit := range edits {
+		if edit.Filename != filename || edit.End < edit.Start {
+			return nil
+		}
+		if i > 0 && edit.Start < edits[i-1].End {
+			return nil
+		}
+	}
+	start, end := edits[0].Start, edits[len(edits)-1].End
+	rng, err := c.rangeOf(filename, start, end)
+	if err != nil {
+		return nil
+	}
+	src := c.contents[filename]
+	var text strings.Builder
+	pos := start
+	for _, edit := range edits {
+		text.Write(src[pos:edit.Start])
+		text.WriteString(edit.New)
+		pos = edit.End
+	}
+	return &rdf.Suggestion{Range: rng, Text: text.String()}
+}
+
+func (c *offsetConverter) rangeOf(filename string, start, end int) (*rdf.Range, error) {
+	lines, err := c.lineOffsets(filename)
+	if err != nil {
+		return nil, err
+	}
+	size := lines[len(lines)-1]
+	if start < 0 || end < start || end > size {
+		return nil, fmt.Errorf("offset out of range of %s: [%d, %d)", filename, start, end)
+	}
+	return &rdf.Range{
+		Start: offsetToPosition(lines, start),
+		End:   offsetToPosition(lines, end),
+	}, nil
+}
+
+// line returns the text of the 1-origin line in the file.
+func (c *offsetConverter) line(filename string, line int) (string, error) {
+	lines, err := c.lineOffsets(filename)
+	if err != nil {
+		return "", err
+	}
+	if line < 1 || line >= len(lines) {
+		return "", fmt.Errorf("line out of range of %s: %d", filename, line)
+	}
+	return string(c.contents[filename][lines[line-1]:lines[line]]), nil
+}
+
+// lineOffsets returns offsets of the beginning of each line in the file. The
+// last element is the size of the file.
+func (c *offsetConverter) lineOffsets(filename string) ([]int, error) {
+	if lines, ok := c.lines[filename]; ok {
+		return lines, nil
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	lines := []int{0}
+	for i, c := range b {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	lines = append(lines, len(b))
+	if c.lines == nil {
+		c.lines = make(map[string][]int)
+		c.contents = make(map[string][]byte)
+	}
+	c.lines[filename] = lines
+	c.contents[filename] = b
+	return lines, nil
+}
+
+func offsetToPosition(lines []int, offset int) *rdf.Position {
+	// Find the last line which begins at or before the offset, excluding the
+	// sentinel (file size).
+	i := sort.Search(len(lines)-1, func(i int) bool { return lines[i] > offset }) - 1
+	return &rdf.Position{
+		Line:   int32(i + 1),
+		Column: int32(offset - lines[i] + 1),
+	}
+}
+
+// GoVetDiagnostic represents a diagnostic of go vet -json output.
+//
+// References:
+//   - https://pkg.go.dev/golang.org/x/tools/go/analysis/internal/analysisflags
+type GoVetDiagnostic struct {
+	Category       string               `json:"category,omitempty"`
+	Posn           string               `json:"posn"`
+	End            string               `json:"end,omitempty"`
+	Message        string               `json:"message"`
+	SuggestedFixes []*GoVetSuggestedFix `json:"suggested_fixes,omitempty"`
+	Related        []*GoVetRelated      `json:"related,omitempty"`
+}
+
+// GoVetSuggestedFix represents a suggested fix which consists of text edits.
+type GoVetSuggestedFix struct {
+	Message string           `json:"message"`
+	Edits   []*GoVetTextEdit `json:"edits"`
+}
+
+// GoVetTextEdit represents a text edit which replaces [start, end) byte
+// offsets of the file with new text.
+type GoVetTextEdit struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	New      string `json:"new"`
+}
+
+// GoVetRelated represents related information of a diagnostic.
+type GoVetRelated struct {
+	Posn    string `json:"posn"`
+	End     string `json:"end,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestGoVetJSONParser(t *testing.T) {
+	const src = `package main
+
+import "fmt"
+
+func main() {
+	s := "こんにちは"
+	fmt.Printf("%d\n", s)
+	for i := 0; i < 3; i++ {
+	}
+}
+`
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	offset := func(s string) int {
+		i := strings.Index(src, s)
+		if i < 0 {
+			t.Fatalf("%q not found", s)
+		}
+		return i
+	}
+	sample := fmt.Sprintf(`# example.com/app
+{
+	"example.com/app": {
+		"printf": [
+			{
+				"posn": "%[1]s:7:2",
+				"message": "fmt.Printf format %%d has arg s of wrong type string",
+				"related": [
+					{"posn": "%[1]s:6:2", "message": "s is declared here"}
+				],
+				"suggested_fixes": [
+					{
+						"message": "Use %%s and update the other file",
+						"edits": [
+							{"filename": "%[1]s", "start": %[5]d, "end": %[6]d, "new": "%%s"},
+							{"filename": "/does/not/exist.go", "start": 0, "end": 1, "new": ""}
+						]
+					}
+				]
+			}
+		],
+		"rangeint": [
+			{
+				"posn": "%[1]s:8:2",
+				"end": "%[1]s:8:26",
+				"message": "for loop can be modernized using range over int",
+				"suggested_fixes": [
+					{
+						"message": "Replace for loop with range 3",
+						"edits": [
+							{"filename": "%[1]s", "start": %[4]d, "end": %[3]d, "new": ""},
+							{"filename": "%[1]s", "start": %[2]d, "end": %[4]d, "new": "range 3"}
+						]
+					}
+				]
+			}
+		],
+		"broken": {"error": "analysis failed"}
+	}
+}
+# example.com/app/sub
+{}
+`, file, offset("i := 0"), offset(" {\n\t}"), offset("i < 3"), offset("%d"), offset("%d")+2)
+
+	ds, err := NewGoVetJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if d.GetOriginalOutput() == "" {
+			t.Errorf("empty original output: %v", d)
+		}
+		d.OriginalOutput = ""
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "fmt.Printf format %d has arg s of wrong type string",
+			Location: &rdf.Location{
+				Path:  file,
+				Range: &rdf.Range{Start: &rdf.Position{Line: 7, Column: 2}},
+			},
+			Code: &rdf.Code{Value: "printf"},
+			RelatedLocations: []*rdf.RelatedLocation{
+				{
+					Message: "s is declared here",
+					Location: &rdf.Location{
+						Path:  file,
+						Range: &rdf.Range{Start: &rdf.Position{Line: 6, Column: 2}},
+					},
+				},
+			},
+		},
+		{
+			Message: "for loop can be modernized using range over int",
+			Location: &rdf.Location{
+				Path: file,
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 8, Column: 2},
+					End:   &rdf.Position{Line: 8, Column: 26},
+				},
+			},
+			Code: &rdf.Code{Value: "rangeint"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 8, Column: 6},
+						End:   &rdf.Position{Line: 8, Column: 24},
+					},
+					Text: "range 3",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, ds, protocmp.Transform()); diff != "" {
+		t.Errorf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestGoVetJSONParser_missingFile(t *testing.T) {
+	const sample = `{"example.com/app": {"assign": [{"posn": "/does/not/exist.go:3:2", "message": "self-assignment of x to x", "suggested_fixes": [{"message": "Remove", "edits": [{"filename": "/does/not/exist.go", "start": 20, "end": 26, "new": ""}]}]}]}}`
+	ds, err := NewGoVetJSONParser().Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	if len(ds[0].GetSuggestions()) != 0 {
+		t.Errorf("want no suggestions, got %v", ds[0].GetSuggestions())
+	}
+}
+
+func TestOffsetConverter_suggestion(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(file, []byte("a := f(x)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := &offsetConverter{}
+	got := files.suggestion(file, []*GoVetTextEdit{
+		{Filename: file, Start: 7, End: 8, New: "y"},
+		{Filename: file, Start: 5, End: 5, New: "g"},
+	})
+	want := &rdf.Suggestion{
+		Range: &rdf.Range{
+			Start: &rdf.Position{Line: 1, Column: 6},
+			End:   &rdf.Position{Line: 1, Column: 9},
+		},
+		Text: "gf(y",
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("suggestion (-want +got):\n%s", diff)
+	}
+	overlapping := []*GoVetTextEdit{
+		{Filename: file, Start: 5, End: 8, New: "g(y"},
+		{Filename: file, Start: 7, End: 9, New: "z)"},
+	}
+	if got := files.suggestion(file, overlapping); got != nil {
+		t.Errorf("want no suggestion for overlapping edits, got %v", got)
+	}
+}
+
+func TestParseGoVetPosn(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantPath string
+		wantPos  *rdf.Position
+	}{
+		{"/src/main.go:10:5", "/src/main.go", &rdf.Position{Line: 10, Column: 5}},
+		{"/src/main.go:10", "/src/main.go", &rdf.Position{Line: 10}},
+		{`C:\src\main.go:10:5`, `C:\src\main.go`, &rdf.Position{Line: 10, Column: 5}},
+		{"-", "-", nil},
+	}
+	for _, tt := range tests {
+		path, pos := parseGoVetPosn(tt.in)
+		if path != tt.wantPath {
+			t.Errorf("parseGoVetPosn(%q) path = %q, want %q", tt.in, path, tt.wantPath)
+		}
+		if diff := cmp.Diff(tt.wantPos, pos, protocmp.Transform()); diff != "" {
+			t.Errorf("parseGoVetPosn(%q) position (-want +got):\n%s", tt.in, diff)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 
 	"github.com/reviewdog/errorformat/fmts"
 
@@ -16,6 +17,50 @@ type Parser interface {
 	Parse(r io.Reader) ([]*rdf.Diagnostic, error)
 }
 
+// StreamParser is a Parser which yields diagnostics as soon as they are
+// parsed, so that callers can process results of large inputs without holding
+// them all in memory.
+type StreamParser interface {
+	Parser
+	// ParseStream returns an iterator of diagnostics in r. If parsing fails,
+	// the error is yielded with nil diagnostic and the iteration ends.
+	ParseStream(r io.Reader) iter.Seq2[*rdf.Diagnostic, error]
+}
+
+// Stream returns an iterator of diagnostics in r parsed by p. It parses r
+// incrementally if p is a StreamParser. Otherwise, it parses the whole input
+// first.
+func Stream(p Parser, r io.Reader) iter.Seq2[*rdf.Diagnostic, error] {
+	if sp, ok := p.(StreamParser); ok {
+		return sp.ParseStream(r)
+	}
+	return func(yield func(*rdf.Diagnostic, error) bool) {
+		ds, err := p.Parse(r)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, d := range ds {
+			if !yield(d, nil) {
+				return
+			}
+		}
+	}
+}
+
+// collect returns all diagnostics yielded by seq. It's used to implement
+// Parse of StreamParser.
+func collect(seq iter.Seq2[*rdf.Diagnostic, error]) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	for d, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
 // Option represents option to create Parser. Either FormatName or
 // Errorformat should be specified.
 type Option struct {
@@ -43,6 +88,18 @@ func New(opt *Option) (Parser, error) {
 		return NewDiffParser(opt.DiffStrip), nil
 	case "sarif":
 		return NewSarifParser(), nil
+	case "clang-sarif":
+		return NewClangSarifParser(), nil
+	case "gcc-json":
+		return NewGCCJSONParser(), nil
+	case "cargo-json":
+		return NewCargoJSONParser(), nil
+	case "govet-json":
+		return NewGoVetJSONParser(), nil
+	case "eslint-json":
+		return NewESLintJSONParser(), nil
+	case "auto":
+		return NewAutoParser(opt.DiffStrip), nil
 	}
 
 	// use defined errorformat
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +42,42 @@ func TestNewParser(t *testing.T) {
 			},
 			typ: &SarifParser{},
 		},
+		{
+			in: &Option{
+				FormatName: "clang-sarif",
+			},
+			typ: &ClangSarifParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "gcc-json",
+			},
+			typ: &GCCJSONParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "cargo-json",
+			},
+			typ: &CargoJSONParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "govet-json",
+			},
+			typ: &GoVetJSONParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "eslint-json",
+			},
+			typ: &ESLintJSONParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "auto",
+			},
+			typ: &AutoParser{},
+		},
 		{ // empty
 			in:      &Option{},
 			wantErr: true,
@@ -73,3 +110,35 @@ func TestNewParser(t *testing.T) {
 		}
 	}
 }
+
+func TestStream(t *testing.T) {
+	const input = `{"message": "msg1", "location": {"path": "a.go"}}
+{"message": "msg2", "location": {"path": "b.go"}}
+{`
+	tests := []struct {
+		name string
+		p    Parser
+		want int
+	}{
+		// StreamParser yields diagnostics before the error.
+		{name: "stream", p: NewRDJSONLParser(), want: 2},
+		{name: "non-stream", p: struct{ Parser }{NewRDJSONLParser()}, want: 0},
+	}
+	for _, tt := range tests {
+		var n int
+		var gotErr error
+		for _, err := range Stream(tt.p, strings.NewReader(input)) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+			n++
+		}
+		if gotErr == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+		if n != tt.want {
+			t.Errorf("%s: got %d diagnostics, want %d", tt.name, n, tt.want)
+		}
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"iter"
 
 	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
-var _ Parser = &RDJSONLParser{}
+var _ StreamParser = &RDJSONLParser{}
 
 // RDJSONLParser is parser for rdjsonl format.
 type RDJSONLParser struct{}
@@ -22,18 +23,26 @@ func NewRDJSONLParser() *RDJSONLParser {
 
 // Parse parses rdjson (JSONL of Diagnostic).
 func (p *RDJSONLParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
-	var results []*rdf.Diagnostic
-	s := bufio.NewScanner(r)
-	for s.Scan() {
-		d := new(rdf.Diagnostic)
-		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(s.Bytes(), d); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal rdjsonl (Diagnostic): %w", err)
-		}
-		if d.GetOriginalOutput() == "" {
-			// TODO(haya14busa): Refactor not to fill in original output.
-			d.OriginalOutput = s.Text()
+	return collect(p.ParseStream(r))
+}
+
+// ParseStream parses rdjsonl line by line.
+func (p *RDJSONLParser) ParseStream(r io.Reader) iter.Seq2[*rdf.Diagnostic, error] {
+	return func(yield func(*rdf.Diagnostic, error) bool) {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			d := new(rdf.Diagnostic)
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(s.Bytes(), d); err != nil {
+				yield(nil, fmt.Errorf("failed to unmarshal rdjsonl (Diagnostic): %w", err))
+				return
+			}
+			if d.GetOriginalOutput() == "" {
+				// TODO(haya14busa): Refactor not to fill in original output.
+				d.OriginalOutput = s.Text()
+			}
+			if !yield(d, nil) {
+				return
+			}
 		}
-		results = append(results, d)
 	}
-	return results, nil
 }
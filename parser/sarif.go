@@ -5,7 +5,10 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/haya14busa/go-sarif/sarif"
 	"github.com/reviewdog/reviewdog/proto/rdf"
@@ -34,18 +37,27 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	for _, run := range slf.Runs {
 		tool := run.Tool
 		driver := tool.Driver
+		// Each run has its own tool, so merged SARIF files from multiple tools
+		// keep their sources.
 		name := driver.Name
+		if name == "" && driver.FullName != nil {
+			name = *driver.FullName
+		}
 		informationURI := ""
 		if driver.InformationURI != nil {
 			informationURI = *driver.InformationURI
 		}
-		baseURIs := run.OriginalURIBaseIDS
+		paths := &sarifPathResolver{
+			baseURIs:  run.OriginalURIBaseIDS,
+			artifacts: run.Artifacts,
+			basedir:   basedir,
+		}
 		rules := map[string]sarif.ReportingDescriptor{}
 		for _, rule := range driver.Rules {
 			rules[rule.ID] = rule
 		}
 		for _, result := range run.Results {
-			if isSuppressed(result.Suppressions) {
+			if isSuppressed(result.Suppressions) || isBaseline(result.BaselineState) {
 				continue
 			}
 			original, err := json.Marshal(result)
@@ -69,7 +81,7 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 			for _, fix := range result.Fixes {
 				for _, artifactChange := range fix.ArtifactChanges {
 					suggestions := []*rdf.Suggestion{}
-					path, err := getPath(artifactChange.ArtifactLocation, baseURIs, basedir)
+					path, err := paths.path(artifactChange.ArtifactLocation)
 					if err != nil {
 						// invalid path
 						return nil, err
@@ -93,7 +105,7 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 
 			relatedLocs := []*rdf.RelatedLocation{}
 			for _, relLoc := range result.RelatedLocations {
-				loc, err := toRDFormatLocation(relLoc, baseURIs, basedir)
+				loc, err := toRDFormatLocation(relLoc, paths)
 				if err != nil {
 					return nil, err
 				}
@@ -105,6 +117,11 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 				}
 				relatedLocs = append(relatedLocs, l)
 			}
+			trace, err := codeFlowLocations(result.CodeFlows, paths)
+			if err != nil {
+				return nil, err
+			}
+			relatedLocs = append(relatedLocs, trace...)
 
 			for _, location := range result.Locations {
 				var code *rdf.Code
@@ -116,7 +133,7 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 						code.Url = *rule.HelpURI
 					}
 				}
-				loc, err := toRDFormatLocation(location, baseURIs, basedir)
+				loc, err := toRDFormatLocation(location, paths)
 				if err != nil {
 					return nil, err
 				}
@@ -140,17 +157,46 @@ func (p *SarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	return ds, nil
 }
 
-func toRDFormatLocation(location sarif.Location,
-	baseURIs map[string]sarif.ArtifactLocation,
-	basedir string,
-) (*rdf.Location, error) {
+// codeFlowLocations converts each thread flow of code flows to an ordered
+// trace of related locations. Steps start at 1 for each thread flow.
+// Locations without physical location (e.g. logical location only) are
+// skipped.
+func codeFlowLocations(codeFlows []sarif.CodeFlow, paths *sarifPathResolver) ([]*rdf.RelatedLocation, error) {
+	var trace []*rdf.RelatedLocation
+	for _, codeFlow := range codeFlows {
+		for _, threadFlow := range codeFlow.ThreadFlows {
+			step := int32(0)
+			for _, tfl := range threadFlow.Locations {
+				if tfl.Location == nil || tfl.Location.PhysicalLocation == nil {
+					continue
+				}
+				loc, err := toRDFormatLocation(*tfl.Location, paths)
+				if err != nil {
+					return nil, err
+				}
+				step++
+				l := &rdf.RelatedLocation{
+					Location: loc,
+					Step:     step,
+				}
+				if tfl.Location.Message != nil {
+					l.Message = getText(*tfl.Location.Message)
+				}
+				trace = append(trace, l)
+			}
+		}
+	}
+	return trace, nil
+}
+
+func toRDFormatLocation(location sarif.Location, paths *sarifPathResolver) (*rdf.Location, error) {
 	physicalLocation := location.PhysicalLocation
 	artifactLocation := physicalLocation.ArtifactLocation
 	loc := sarif.ArtifactLocation{}
 	if artifactLocation != nil {
 		loc = *artifactLocation
 	}
-	path, err := getPath(loc, baseURIs, basedir)
+	path, err := paths.path(loc)
 	if err != nil {
 		// invalid path
 		return nil, err
@@ -165,36 +211,109 @@ func toRDFormatLocation(location sarif.Location,
 	}, nil
 }
 
-func getPath(
-	l sarif.ArtifactLocation,
-	baseURIs map[string]sarif.ArtifactLocation,
-	basedir string,
-) (string, error) {
+// sarifPathResolver resolves artifact locations of a run to file paths.
+type sarifPathResolver struct {
+	baseURIs  map[string]sarif.ArtifactLocation
+	artifacts []sarif.Artifact
+	basedir   string
+}
+
+// path returns a file path of the artifact location. Relative URIs are
+// resolved against originalUriBaseIds (which may refer to another base URI),
+// and the location refers to run.artifacts if it has an index. Paths under
+// the current directory are returned as relative paths.
+func (r *sarifPathResolver) path(l sarif.ArtifactLocation) (string, error) {
+	if l.Index != nil && *l.Index >= 0 && int(*l.Index) < len(r.artifacts) {
+		if a := r.artifacts[*l.Index].Location; a != nil {
+			if l.URI == nil {
+				l.URI = a.URI
+			}
+			if l.URIBaseID == nil {
+				l.URIBaseID = a.URIBaseID
+			}
+		}
+	}
 	uri := ""
 	if l.URI != nil {
 		uri = *l.URI
 	}
-	urlBaseID := ""
-	if l.URIBaseID != nil {
-		urlBaseID = *l.URIBaseID
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
 	}
-	baseURI := baseURIs[urlBaseID].URI
-	if baseURI != nil && *baseURI != "" {
-		if u, err := url.JoinPath(*baseURI, uri); err == nil {
-			uri = u
+	if !u.IsAbs() && l.URIBaseID != nil {
+		base, err := r.baseURI(*l.URIBaseID, len(r.baseURIs))
+		if err != nil {
+			return "", err
+		}
+		if base != nil {
+			u = resolveReference(base, u)
 		}
 	}
-	parse, err := url.Parse(uri)
-	if err != nil {
-		return "", err
+	path := u.Path
+	if u.Scheme == "file" && windowsDrivePath.MatchString(path) {
+		// file:///C:/path/to/file
+		path = path[1:]
 	}
-	path := parse.Path
-	if relpath, err := filepath.Rel(basedir, path); err == nil {
+	path = filepath.FromSlash(path)
+	if relpath, err := filepath.Rel(r.basedir, path); err == nil {
 		path = relpath
 	}
 	return path, nil
 }
 
+var windowsDrivePath = regexp.MustCompile(`^/[a-zA-Z]:/`)
+
+// baseURI returns the URI of the base id. It returns nil if the base id is
+// not defined or has no URI (i.e. the consumer should decide the base).
+// Relative base URIs are resolved against their base ids up to the given
+// depth to avoid infinite loop.
+func (r *sarifPathResolver) baseURI(id string, depth int) (*url.URL, error) {
+	l, ok := r.baseURIs[id]
+	if !ok || l.URI == nil || *l.URI == "" || depth < 0 {
+		return nil, nil
+	}
+	u, err := url.Parse(*l.URI)
+	if err != nil {
+		return nil, err
+	}
+	// Base URIs must end with a slash, but some tools omit it.
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+	if !u.IsAbs() && l.URIBaseID != nil {
+		parent, err := r.baseURI(*l.URIBaseID, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		if parent != nil {
+			u = resolveReference(parent, u)
+		}
+	}
+	return u, nil
+}
+
+// resolveReference resolves the relative URI against the base URI. Relative
+// base URIs without a parent (e.g. "src/") are joined with the URI, as
+// url.URL.ResolveReference makes the result rooted (e.g. "/src/a.go").
+func resolveReference(base, ref *url.URL) *url.URL {
+	if base.IsAbs() || strings.HasPrefix(base.Path, "/") || strings.HasPrefix(ref.Path, "/") {
+		return base.ResolveReference(ref)
+	}
+	u := *ref
+	u.Path = path.Join(base.Path, ref.Path)
+	u.RawPath = ""
+	return &u
+}
+
+// isBaseline reports whether a SARIF result should be skipped because it's
+// not new compared to the baseline. "absent" results don't exist anymore and
+// "unchanged" results were already reported.
+func isBaseline(s *sarif.BaselineState) bool {
+	return s != nil && (*s == sarif.Absent || *s == sarif.Unchanged)
+}
+
 // isSuppressed reports whether a SARIF result should be skipped because the
 // tool already accepted a suppression for it. Per SARIF 2.1.0 §3.35.3, a
 // Suppression's status defaults to "accepted" when the property is absent;
@@ -342,3 +461,49 @@ func getRdfRange(r sarif.Region) *rdf.Range {
 	}
 	return rng
 }
+
+var _ Parser = &ClangSarifParser{}
+
+// ClangSarifParser is parser for Clang SARIF output
+// (-fdiagnostics-format=sarif).
+//
+// Clang reports notes as separate results with "note" level following the
+// error or warning they belong to, so they are converted to related locations
+// of the error or warning. Other results (e.g. notes without a preceding
+// error or warning, or remarks with "none" level) are kept as diagnostics.
+type ClangSarifParser struct {
+	sarif SarifParser
+}
+
+// NewClangSarifParser returns a new ClangSarifParser.
+func NewClangSarifParser() *ClangSarifParser {
+	return &ClangSarifParser{}
+}
+
+func (p *ClangSarifParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	results, err := p.sarif.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var ds []*rdf.Diagnostic
+	// parent is the last error or warning which following notes belong to.
+	var parent *rdf.Diagnostic
+	for _, d := range results {
+		switch d.GetSeverity() {
+		case rdf.Severity_INFO:
+			if parent != nil && d.GetSource().GetName() == parent.GetSource().GetName() {
+				parent.RelatedLocations = append(parent.RelatedLocations, &rdf.RelatedLocation{
+					Message:  d.GetMessage(),
+					Location: d.GetLocation(),
+				})
+				continue
+			}
+		case rdf.Severity_ERROR, rdf.Severity_WARNING:
+			parent = d
+		default:
+			parent = nil
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -134,6 +135,203 @@ func TestSarifParser_Suppressions(t *testing.T) {
 	}
 }
 
+func TestSarifParser_multipleRuns(t *testing.T) {
+	input := fmt.Sprintf(`{
+	"version": "2.1.0",
+	"runs": [
+		{
+			"tool": {"driver": {"name": "tool-a"}},
+			"originalUriBaseIds": {
+				"PROJECTROOT": {"uri": "file://%s/"},
+				"SRCROOT": {"uri": "src", "uriBaseId": "PROJECTROOT"}
+			},
+			"artifacts": [
+				{"location": {"uri": "indexed.go", "uriBaseId": "SRCROOT"}}
+			],
+			"results": [
+				{
+					"message": {"text": "base id chain"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "a%%20b.go", "uriBaseId": "SRCROOT"}, "region": {"startLine": 1}}}]
+				},
+				{
+					"message": {"text": "artifact index"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"index": 0}, "region": {"startLine": 2}}}]
+				},
+				{
+					"message": {"text": "file URI"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "file://%s/b.go"}, "region": {"startLine": 3}}}]
+				},
+				{
+					"message": {"text": "unchanged"},
+					"baselineState": "unchanged",
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "c.go"}, "region": {"startLine": 4}}}]
+				},
+				{
+					"message": {"text": "absent"},
+					"baselineState": "absent",
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "c.go"}, "region": {"startLine": 5}}}]
+				}
+			]
+		},
+		{
+			"tool": {"driver": {"fullName": "Tool B"}},
+			"results": [
+				{
+					"message": {"text": "new"},
+					"baselineState": "new",
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "d.go"}, "region": {"startLine": 6}}}]
+				}
+			]
+		}
+	]
+}`, basedir(), basedir())
+	diagnostics, err := NewSarifParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		Message, Path, Source string
+	}
+	var got []result
+	for _, d := range diagnostics {
+		got = append(got, result{d.GetMessage(), d.GetLocation().GetPath(), d.GetSource().GetName()})
+	}
+	want := []result{
+		{"base id chain", "src/a b.go", "tool-a"},
+		{"artifact index", "src/indexed.go", "tool-a"},
+		{"file URI", "b.go", "tool-a"},
+		{"new", "d.go", "Tool B"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diagnostics (-want +got):\n%s", diff)
+	}
+}
+
+func TestSarifParser_relativeBaseURI(t *testing.T) {
+	const input = `{
+	"version": "2.1.0",
+	"runs": [
+		{
+			"tool": {"driver": {"name": "tool"}},
+			"originalUriBaseIds": {
+				"SRC": {"uri": "src/"},
+				"ROOT": {"uri": "project"},
+				"PKG": {"uri": "pkg", "uriBaseId": "ROOT"}
+			},
+			"results": [
+				{
+					"message": {"text": "relative base"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "a.go", "uriBaseId": "SRC"}}}]
+				},
+				{
+					"message": {"text": "relative base chain"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "b.go", "uriBaseId": "PKG"}}}]
+				}
+			]
+		}
+	]
+}`
+	diagnostics, err := NewSarifParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.GetLocation().GetPath())
+	}
+	want := []string{filepath.FromSlash("src/a.go"), filepath.FromSlash("project/pkg/b.go")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("paths (-want +got):\n%s", diff)
+	}
+}
+
+func TestClangSarifParser(t *testing.T) {
+	const input = `{
+	"version": "2.1.0",
+	"runs": [
+		{
+			"tool": {"driver": {"name": "clang", "rules": [
+				{"id": "4589", "defaultConfiguration": {"level": "error"}},
+				{"id": "1234", "defaultConfiguration": {"level": "note"}}
+			]}},
+			"results": [
+				{
+					"ruleId": "4589",
+					"ruleIndex": 0,
+					"message": {"text": "use of undeclared identifier 'fo'; did you mean 'foo'?"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 5, "startColumn": 10}}}]
+				},
+				{
+					"ruleId": "1234",
+					"ruleIndex": 1,
+					"message": {"text": "'foo' declared here"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 2, "startColumn": 5}}}]
+				}
+			]
+		}
+	]
+}`
+	ds, err := NewClangSarifParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(ds))
+	}
+	rel := ds[0].GetRelatedLocations()
+	if len(rel) != 1 || rel[0].GetMessage() != "'foo' declared here" || rel[0].GetLocation().GetRange().GetStart().GetLine() != 2 {
+		t.Errorf("note is not converted to a related location: %v", rel)
+	}
+}
+
+func TestClangSarifParser_independentResults(t *testing.T) {
+	const input = `{
+	"version": "2.1.0",
+	"runs": [
+		{
+			"tool": {"driver": {"name": "clang"}},
+			"results": [
+				{
+					"level": "note",
+					"message": {"text": "standalone note"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 1}}}]
+				},
+				{
+					"level": "warning",
+					"message": {"text": "unused variable 'x'"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 3}}}]
+				},
+				{
+					"level": "none",
+					"message": {"text": "remark"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 5}}}]
+				},
+				{
+					"level": "note",
+					"message": {"text": "note after remark"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.c"}, "region": {"startLine": 6}}}]
+				}
+			]
+		}
+	]
+}`
+	ds, err := NewClangSarifParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range ds {
+		got = append(got, d.GetMessage())
+		if rel := d.GetRelatedLocations(); len(rel) != 0 {
+			t.Errorf("unexpected related locations of %q: %v", d.GetMessage(), rel)
+		}
+	}
+	want := []string{"standalone note", "unused variable 'x'", "remark", "note after remark"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("messages (-want +got):\n%s", diff)
+	}
+}
+
 func basedir() string {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -527,5 +725,127 @@ var fixtures = [][]string{{
 			}
     }
   ]
+}`},
+	{`{
+	"runs": [
+		{
+			"results": [
+				{
+					"ruleId": "py/code-injection",
+					"message": {
+						"text": "This code execution depends on a user-provided value."
+					},
+					"locations": [
+						{
+							"physicalLocation": {
+								"artifactLocation": {
+									"uri": "app.py"
+								},
+								"region": {
+									"startLine": 12
+								}
+							}
+						}
+					],
+					"codeFlows": [
+						{
+							"threadFlows": [
+								{
+									"locations": [
+										{
+											"location": {
+												"physicalLocation": {
+													"artifactLocation": {
+														"uri": "app.py"
+													},
+													"region": {
+														"startLine": 5
+													}
+												},
+												"message": {
+													"text": "request.args"
+												}
+											}
+										},
+										{
+											"location": {
+												"logicalLocations": [
+													{
+														"fullyQualifiedName": "flask.request"
+													}
+												]
+											}
+										},
+										{
+											"location": {
+												"physicalLocation": {
+													"artifactLocation": {
+														"uri": "app.py"
+													},
+													"region": {
+														"startLine": 12
+													}
+												},
+												"message": {
+													"text": "code"
+												}
+											}
+										}
+									]
+								}
+							]
+						}
+					]
+				}
+			],
+			"tool": {
+				"driver": {
+					"name": "CodeQL"
+				}
+			}
+		}
+	]
+}`, `{
+	"message": "This code execution depends on a user-provided value.",
+	"location": {
+		"path": "app.py",
+		"range": {
+			"start": {
+				"line": 12
+			}
+		}
+	},
+	"source": {
+		"name": "CodeQL"
+	},
+	"code": {
+		"value": "py/code-injection"
+	},
+	"relatedLocations": [
+		{
+			"message": "request.args",
+			"location": {
+				"path": "app.py",
+				"range": {
+					"start": {
+						"line": 5
+					}
+				}
+			},
+			"step": 1
+		},
+		{
+			"message": "code",
+			"location": {
+				"path": "app.py",
+				"range": {
+					"start": {
+						"line": 12
+					}
+				}
+			},
+			"step": 2
+		}
+	]
 }`},
 }
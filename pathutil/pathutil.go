@@ -47,10 +47,15 @@ func NormalizeDiffPath(diffpath string, strip int) string {
 // NormalizePathInResults normalize file path in RDFormat results.
 func NormalizePathInResults(results []*rdf.Diagnostic, cwd, gitRelWorkdir string) {
 	for _, result := range results {
-		normalizeLocation(result.GetLocation(), cwd, gitRelWorkdir)
-		for _, rel := range result.GetRelatedLocations() {
-			normalizeLocation(rel.GetLocation(), cwd, gitRelWorkdir)
-		}
+		NormalizePathInResult(result, cwd, gitRelWorkdir)
+	}
+}
+
+// NormalizePathInResult normalize file path in a RDFormat result.
+func NormalizePathInResult(result *rdf.Diagnostic, cwd, gitRelWorkdir string) {
+	normalizeLocation(result.GetLocation(), cwd, gitRelWorkdir)
+	for _, rel := range result.GetRelatedLocations() {
+		normalizeLocation(rel.GetLocation(), cwd, gitRelWorkdir)
 	}
 }
 
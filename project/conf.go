@@ -21,6 +21,9 @@ type Runner struct {
 	Errorformat []string
 	// Report Level for this runner. ("info", "warning", "error")
 	Level string
+	// Go text/template of comment body for this runner. It overrides
+	// -comment-template flag.
+	CommentTemplate string `yaml:"comment_template"`
 }
 
 // Parse parses reviewdog config in yaml format.
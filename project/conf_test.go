@@ -20,6 +20,7 @@ runner:
     cmd: go tool vet -all -shadowstrict .
     format: govet
     level: warning
+    comment_template: "{{.Message}} (see https://example.com/rules/{{.Code}})"
   namekey:
     cmd: echo 'name'
     name: nameoverwritten
@@ -36,10 +37,11 @@ runner:
 				Level:       "info",
 			},
 			"govet": {
-				Cmd:    "go tool vet -all -shadowstrict .",
-				Format: "govet",
-				Name:   "govet",
-				Level:  "warning",
+				Cmd:             "go tool vet -all -shadowstrict .",
+				Format:          "govet",
+				Name:            "govet",
+				Level:           "warning",
+				CommentTemplate: "{{.Message}} (see https://example.com/rules/{{.Code}})",
 			},
 			"namekey": {
 				Cmd:    "echo 'name'",
@@ -10,6 +10,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"text/template"
 
 	"golang.org/x/sync/errgroup"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/parser"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 )
 
 // RunAndParse runs commands and parse results. Returns map of tool name to check results.
@@ -94,6 +96,11 @@ func RunAndParse(ctx context.Context, conf *Config, runners map[string]bool, def
 // Run runs reviewdog tasks based on Config.
 func Run(ctx context.Context, conf *Config, runners map[string]bool, c reviewdog.CommentService, d reviewdog.DiffService,
 	teeMode bool, filterMode filter.Mode, failLevel reviewdog.FailLevel) error {
+	runnerTmpls, err := runnerCommentTemplates(conf)
+	if err != nil {
+		return err
+	}
+
 	results, err := RunAndParse(ctx, conf, runners, "", teeMode) // Level is not used.
 	if err != nil {
 		return err
@@ -118,8 +125,10 @@ func Run(ctx context.Context, conf *Config, runners map[string]bool, c reviewdog
 		if ncs, ok := c.(reviewdog.NamedCommentService); ok {
 			ncs.SetTool(toolname, result.Level)
 		}
+		rd := reviewdog.NewReviewdog(toolname, nil, c, d, filterMode, failLevel)
+		rd.SetCommentTemplate(runnerTmpls[toolname])
 		// Note: CommentService shouldn't be run concurrently with different tool.
-		if err := reviewdog.RunFromResult(ctx, c, result.Diagnostics, filediffs, d.Strip(), toolname, filterMode, failLevel); err != nil {
+		if err := rd.RunFromResult(ctx, result.Diagnostics, filediffs, d.Strip()); err != nil {
 			errs = append(errs, err)
 		}
 	})
@@ -161,6 +170,23 @@ func checkUnknownRunner(specifiedRunners map[string]bool, usedRunners []string)
 	return nil
 }
 
+// runnerCommentTemplates returns comment templates by runner name.
+func runnerCommentTemplates(conf *Config) (map[string]*template.Template, error) {
+	tmpls := make(map[string]*template.Template)
+	for key, runner := range conf.Runner {
+		if runner.CommentTemplate == "" {
+			continue
+		}
+		name := getRunnerName(key, runner)
+		tmpl, err := commentutil.ParseTemplate(name, runner.CommentTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("runner %s: %w", name, err)
+		}
+		tmpls[name] = tmpl
+	}
+	return tmpls, nil
+}
+
 func getRunnerName(key string, runner *Runner) string {
 	if runner.Name != "" {
 		return runner.Name
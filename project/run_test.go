@@ -9,8 +9,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/reviewdog/reviewdog"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 )
 
 type fakeDiffService struct {
@@ -296,6 +299,66 @@ func TestRun(t *testing.T) {
 	})
 }
 
+func TestRun_commentTemplate(t *testing.T) {
+	ctx := context.Background()
+	ds := &fakeDiffService{
+		FakeDiff: func() ([]byte, error) {
+			return []byte(""), nil
+		},
+	}
+	conf := &Config{
+		Runner: map[string]*Runner{
+			"default": {
+				Cmd:         "echo 'reviewdog.go:14:1:default'",
+				Errorformat: []string{`%f:%l:%c:%m`},
+			},
+			"custom": {
+				Cmd:             "echo 'reviewdog.go:14:1:custom'",
+				Errorformat:     []string{`%f:%l:%c:%m`},
+				CommentTemplate: "custom template",
+			},
+		},
+	}
+	t.Run("per runner template", func(t *testing.T) {
+		got := make(map[string]string)
+		cs := &fakeCommentService{
+			FakePost: func(c *reviewdog.Comment) error {
+				if c.Template == nil {
+					got[c.ToolName] = "<no template>"
+				} else {
+					got[c.ToolName] = commentutil.MarkdownComment(c)
+				}
+				return nil
+			},
+		}
+		if err := Run(ctx, conf, nil, cs, ds, false, filter.ModeNoFilter, reviewdog.FailLevelNone); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"default": "<no template>",
+			"custom":  "custom template",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("comment bodies have diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		conf := &Config{
+			Runner: map[string]*Runner{
+				"invalid": {
+					Cmd:             "echo 'hi'",
+					Errorformat:     []string{`%f:%l:%c:%m`},
+					CommentTemplate: "{{.Message",
+				},
+			},
+		}
+		if err := Run(ctx, conf, nil, nil, ds, false, filter.ModeAdded, reviewdog.FailLevelNone); err == nil {
+			t.Error("want error for invalid comment template")
+		}
+	})
+}
+
 func TestFilteredEnviron(t *testing.T) {
 	names := [...]string{
 		"REVIEWDOG_GITHUB_API_TOKEN",
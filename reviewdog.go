@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/template"
 
 	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/filter"
@@ -25,6 +26,7 @@ type Reviewdog struct {
 	d          DiffService
 	filterMode filter.Mode
 	failLevel  FailLevel
+	tmpl       *template.Template
 }
 
 // NewReviewdog returns a new Reviewdog.
@@ -32,16 +34,25 @@ func NewReviewdog(toolname string, p parser.Parser, c CommentService, d DiffServ
 	return &Reviewdog{p: p, c: c, d: d, toolname: toolname, filterMode: filterMode, failLevel: failLevel}
 }
 
+// SetCommentTemplate sets an optional comment body template (see
+// Comment.Template).
+func (w *Reviewdog) SetCommentTemplate(tmpl *template.Template) {
+	w.tmpl = tmpl
+}
+
 // RunFromResult creates a new Reviewdog and runs it with check results.
 func RunFromResult(ctx context.Context, c CommentService, results []*rdf.Diagnostic,
 	filediffs []*diff.FileDiff, strip int, toolname string, filterMode filter.Mode, failLevel FailLevel) error {
-	return (&Reviewdog{c: c, toolname: toolname, filterMode: filterMode, failLevel: failLevel}).runFromResult(ctx, results, filediffs, strip)
+	return NewReviewdog(toolname, nil, c, nil, filterMode, failLevel).RunFromResult(ctx, results, filediffs, strip)
 }
 
 // Comment represents a reported result as a comment.
 type Comment struct {
 	Result   *filter.FilteredDiagnostic
 	ToolName string
+	// Template is an optional template of comment body. It's executed with
+	// commentutil.TemplateData by commentutil.MarkdownComment.
+	Template *template.Template
 }
 
 // CommentService is an interface which posts Comment.
@@ -72,61 +83,99 @@ type NamedCommentService interface {
 	SetTool(toolName string, level string)
 }
 
+// FinishCommentService has work to do once after all reviewdog runs, such as
+// posting a summary of all tools. Finish() should be called once at the end,
+// after Flush() of the last run.
+type FinishCommentService interface {
+	CommentService
+	Finish(context.Context) error
+}
+
 // DiffService is an interface which get diff.
 type DiffService interface {
 	Diff(context.Context) ([]byte, error)
 	Strip() int
 }
 
-func (w *Reviewdog) runFromResult(ctx context.Context, results []*rdf.Diagnostic,
+// RunFromResult runs Reviewdog with check results instead of parsing the
+// input and getting the diff.
+func (w *Reviewdog) RunFromResult(ctx context.Context, results []*rdf.Diagnostic,
 	filediffs []*diff.FileDiff, strip int) error {
-	wd, err := os.Getwd()
+	rep, err := w.newResultReporter(filediffs, strip)
 	if err != nil {
 		return err
 	}
+	for _, result := range results {
+		if err := rep.report(ctx, result); err != nil {
+			return err
+		}
+	}
+	return rep.finish(ctx)
+}
+
+// resultReporter filters results by diff and posts them one by one.
+type resultReporter struct {
+	w          *Reviewdog
+	df         *filter.DiffFilter
+	wd         string
+	relDir     string
+	shouldFail bool
+}
+
+func (w *Reviewdog) newResultReporter(filediffs []*diff.FileDiff, strip int) (*resultReporter, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
 
 	relDir := ""
 	if w.c.ShouldPrependGitRelDir() {
 		gitRelWorkdir, err := serviceutil.GitRelWorkdir()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		relDir = gitRelWorkdir
 	}
 
-	pathutil.NormalizePathInResults(results, wd, relDir)
-
-	checks := filter.FilterCheck(results, filediffs, strip, wd, w.filterMode)
-	shouldFail := false
+	return &resultReporter{
+		w:      w,
+		df:     filter.NewDiffFilter(filediffs, strip, wd, w.filterMode),
+		wd:     wd,
+		relDir: relDir,
+	}, nil
+}
 
-	for _, check := range checks {
-		comment := &Comment{
-			Result:   check,
-			ToolName: w.toolname,
-		}
-		if !check.ShouldReport {
-			if fc, ok := w.c.(FilteredCommentService); ok {
-				if err := fc.PostFiltered(ctx, comment); err != nil {
-					return err
-				}
-			} else {
-				continue
-			}
-		} else {
-			if err := w.c.Post(ctx, comment); err != nil {
-				return err
-			}
-			shouldFail = shouldFail || w.failLevel.ShouldFail(check.Diagnostic.GetSeverity())
+func (rep *resultReporter) report(ctx context.Context, result *rdf.Diagnostic) error {
+	w := rep.w
+	pathutil.NormalizePathInResult(result, rep.wd, rep.relDir)
+	check := rep.df.FilterDiagnostic(result)
+	comment := &Comment{
+		Result:   check,
+		ToolName: w.toolname,
+		Template: w.tmpl,
+	}
+	if !check.ShouldReport {
+		if fc, ok := w.c.(FilteredCommentService); ok {
+			return fc.PostFiltered(ctx, comment)
 		}
+		return nil
 	}
+	if err := w.c.Post(ctx, comment); err != nil {
+		return err
+	}
+	rep.shouldFail = rep.shouldFail || w.failLevel.ShouldFail(check.Diagnostic.GetSeverity())
+	return nil
+}
 
+func (rep *resultReporter) finish(ctx context.Context) error {
+	w := rep.w
 	if bulk, ok := w.c.(BulkCommentService); ok {
 		if err := bulk.Flush(ctx); err != nil {
 			return err
 		}
 	}
 
-	if shouldFail {
+	if rep.shouldFail {
 		return fmt.Errorf("found at least one issue with severity greater than or equal to the given level: %s", w.failLevel.String())
 	}
 
@@ -134,21 +183,72 @@ func (w *Reviewdog) runFromResult(ctx context.Context, results []*rdf.Diagnostic
 }
 
 // Run runs Reviewdog application.
+//
+// It fetches diff concurrently with parsing the input, and reports results as
+// they are parsed if the parser is a parser.StreamParser, so that memory usage
+// doesn't grow with the size of the input.
+//
+// As a consequence, if parsing fails in the middle of the input, results
+// parsed before the error have already been posted to comment services which
+// aren't BulkCommentService. BulkCommentService isn't flushed on errors, so
+// nothing is posted to it.
 func (w *Reviewdog) Run(ctx context.Context, r io.Reader) error {
-	results, err := w.p.Parse(r)
-	if err != nil {
-		return fmt.Errorf("parse error: %w", err)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type diffResult struct {
+		filediffs []*diff.FileDiff
+		err       error
+	}
+	diffc := make(chan diffResult, 1)
+	go func() {
+		filediffs, err := w.fetchDiff(ctx)
+		diffc <- diffResult{filediffs: filediffs, err: err}
+	}()
+
+	var rep *resultReporter
+	// startReport waits for diff and creates the reporter on the first call.
+	startReport := func() error {
+		if rep != nil {
+			return nil
+		}
+		res := <-diffc
+		if res.err != nil {
+			return res.err
+		}
+		var err error
+		rep, err = w.newResultReporter(res.filediffs, w.d.Strip())
+		return err
 	}
 
+	for result, err := range parser.Stream(w.p, r) {
+		if err != nil {
+			return fmt.Errorf("parse error: %w", err)
+		}
+		if err := startReport(); err != nil {
+			return err
+		}
+		if err := rep.report(ctx, result); err != nil {
+			return err
+		}
+	}
+	// Wait for diff even if there are no results as bulk comment services may
+	// clean up outdated comments on Flush.
+	if err := startReport(); err != nil {
+		return err
+	}
+	return rep.finish(ctx)
+}
+
+func (w *Reviewdog) fetchDiff(ctx context.Context) ([]*diff.FileDiff, error) {
 	d, err := w.d.Diff(ctx)
 	if err != nil {
-		return fmt.Errorf("fail to get diff: %w", err)
+		return nil, fmt.Errorf("fail to get diff: %w", err)
 	}
 
 	filediffs, err := diff.ParseMultiFile(bytes.NewReader(d))
 	if err != nil {
-		return fmt.Errorf("fail to parse diff: %w", err)
+		return nil, fmt.Errorf("fail to parse diff: %w", err)
 	}
-
-	return w.runFromResult(ctx, results, filediffs, w.d.Strip())
+	return filediffs, nil
 }
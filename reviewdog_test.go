@@ -2,7 +2,10 @@ package reviewdog
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -223,3 +226,78 @@ golint.new.go:11:1: comment on exported function F2 should be of the form "F2 ..
 		t.Errorf("'input data has violations' expected, but got %v", err)
 	}
 }
+
+func TestReviewdog_Run_stream(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1 +1,2 @@
+ package a
++var V int
+`
+	posted := make(chan string)
+	c := &testWriter{
+		FakePost: func(c *Comment) error {
+			posted <- c.Result.Diagnostic.GetMessage()
+			return nil
+		},
+	}
+	pr, pw := io.Pipe()
+	efm, _ := errorformat.NewErrorformat([]string{`%f:%l:%c: %m`})
+	app := NewReviewdog("tool name", parser.NewErrorformatParser(efm), c, NewDiffString(difftext, 1), filter.ModeAdded, FailLevelDefault)
+	errc := make(chan error, 1)
+	go func() { errc <- app.Run(context.Background(), pr) }()
+
+	// The result must be reported before the input ends.
+	fmt.Fprintln(pw, "a.go:2:5: msg1")
+	if got := <-posted; got != "msg1" {
+		t.Errorf("got %q, want msg1", got)
+	}
+	fmt.Fprintln(pw, "a.go:1:1: not in diff")
+	fmt.Fprintln(pw, "a.go:2:1: msg2")
+	pw.Close()
+	if got := <-posted; got != "msg2" {
+		t.Errorf("got %q, want msg2", got)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReviewdog_Run_parse_error(t *testing.T) {
+	c := &testWriter{FakePost: func(*Comment) error { return nil }}
+	app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, NewDiffString("", 1), filter.ModeAdded, FailLevelDefault)
+	err := app.Run(context.Background(), strings.NewReader("{\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "parse error: ") {
+		t.Errorf("got %v, want parse error", err)
+	}
+}
+
+func TestReviewdog_Run_parse_error_after_results(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1 +1,2 @@
+ package a
++var V int
+`
+	var posted []string
+	c := &testWriter{
+		FakePost: func(c *Comment) error {
+			posted = append(posted, c.Result.Diagnostic.GetMessage())
+			return nil
+		},
+	}
+	app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, NewDiffString(difftext, 1), filter.ModeAdded, FailLevelDefault)
+	input := `{"message": "msg1", "location": {"path": "a.go", "range": {"start": {"line": 2}}}}
+{"message": "broken",
+`
+	err := app.Run(context.Background(), strings.NewReader(input))
+	if err == nil || !strings.HasPrefix(err.Error(), "parse error: ") {
+		t.Errorf("got %v, want parse error", err)
+	}
+	// Results before the parse error are already posted.
+	if want := []string{"msg1"}; !slices.Equal(posted, want) {
+		t.Errorf("posted %v, want %v", posted, want)
+	}
+}
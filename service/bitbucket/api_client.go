@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/reviewdog/reviewdog"
 )
@@ -30,6 +31,36 @@ type AnnotationsRequest struct {
 	Comments   []*reviewdog.Comment
 }
 
+// ClientOption is an option of NewCloudAPIClient and NewServerAPIClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+// WithTransportWrapper makes the client wrap its HTTP transport with the given
+// function (e.g. for dry-run mode).
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		o.wrapTransport = wrap
+	}
+}
+
+// wrapTransport returns tr wrapped by the transport wrapper option if any.
+func wrapTransport(tr http.RoundTripper, opts []ClientOption) http.RoundTripper {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.wrapTransport == nil {
+		return tr
+	}
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	return o.wrapTransport(tr)
+}
+
 // APIClient is client for Bitbucket Code Insights API
 type APIClient interface {
 
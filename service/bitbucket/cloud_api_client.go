@@ -31,7 +31,7 @@ type CloudAPIClient struct {
 }
 
 // NewCloudAPIClient creates client for Bitbucket Cloud Insights API
-func NewCloudAPIClient(isInPipeline bool, isInPipe bool) APIClient {
+func NewCloudAPIClient(isInPipeline bool, isInPipe bool, opts ...ClientOption) APIClient {
 	httpClient := &http.Client{
 		Timeout: httpTimeout,
 	}
@@ -63,6 +63,8 @@ func NewCloudAPIClient(isInPipeline bool, isInPipe bool) APIClient {
 		}
 	}
 
+	httpClient.Transport = wrapTransport(httpClient.Transport, opts)
+
 	return NewCloudAPIClientWithConfigurations(httpClient, server)
 }
 
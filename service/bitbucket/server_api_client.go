@@ -16,9 +16,10 @@ type ServerAPIClient struct {
 }
 
 // NewServerAPIClient creates client for Bitbucket Server Code Insights API
-func NewServerAPIClient() APIClient {
+func NewServerAPIClient(opts ...ClientOption) APIClient {
 	httpClient := &http.Client{
-		Timeout: httpTimeout,
+		Timeout:   httpTimeout,
+		Transport: wrapTransport(nil, opts),
 	}
 
 	config := insights.NewConfiguration()
@@ -55,8 +55,14 @@ func (p PostedComments) DebugLog() {
 // BodyPrefix is prefix text of comment body.
 const BodyPrefix = `<sub>reported by [reviewdog](https://github.com/reviewdog/reviewdog) :dog:</sub><br>`
 
-// MarkdownComment creates comment body markdown.
+// MarkdownComment creates comment body markdown. It executes
+// reviewdog.Comment.Template if it's set.
 func MarkdownComment(c *reviewdog.Comment) string {
+	if c.Template != nil {
+		if body, ok := executeTemplate(c); ok {
+			return body
+		}
+	}
 	var sb strings.Builder
 	if s := severity(c); s != "" {
 		sb.WriteString(s)
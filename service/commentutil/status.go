@@ -0,0 +1,135 @@
+package commentutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+// maxStatusDescriptionLength is the max length of commit status descriptions.
+// GitHub rejects longer descriptions.
+const maxStatusDescriptionLength = 140
+
+// CommitStatus holds comments of the current tool and builds a commit status
+// of the tool from them. Commit status reporters embed it and implement Flush
+// with FlushStatus and their API call.
+type CommitStatus struct {
+	toolName  string
+	failLevel reviewdog.FailLevel
+
+	muComments   sync.Mutex
+	postComments []*reviewdog.Comment
+}
+
+// Status represents a commit status of a tool.
+type Status struct {
+	// Name is the name of the status (context in GitHub and Gitea). It's the
+	// tool name or "reviewdog" if the tool name is empty.
+	Name        string
+	Failed      bool
+	Description string
+}
+
+// NewCommitStatus returns a new CommitStatus.
+func NewCommitStatus(toolName string, failLevel reviewdog.FailLevel) *CommitStatus {
+	return &CommitStatus{toolName: toolName, failLevel: failLevel}
+}
+
+// Post accepts a comment and holds it. FlushStatus builds the status from
+// held comments.
+func (s *CommitStatus) Post(_ context.Context, c *reviewdog.Comment) error {
+	s.muComments.Lock()
+	defer s.muComments.Unlock()
+	s.postComments = append(s.postComments, c)
+	return nil
+}
+
+func (*CommitStatus) ShouldPrependGitRelDir() bool { return true }
+
+func (s *CommitStatus) SetTool(toolName string, _ string) {
+	s.toolName = toolName
+}
+
+// FlushStatus builds the status of the current tool and sets it with set.
+// The context passed to set marks requests retryable as setting a commit
+// status is idempotent. Held comments are cleared even if set fails.
+func (s *CommitStatus) FlushStatus(ctx context.Context, set func(context.Context, *Status) error) error {
+	s.muComments.Lock()
+	defer s.muComments.Unlock()
+	defer func() { s.postComments = nil }()
+	return set(serviceutil.WithRetryable(ctx), s.status())
+}
+
+func (s *CommitStatus) status() *Status {
+	name := s.toolName
+	if name == "" {
+		name = "reviewdog"
+	}
+	desc := StatusDescription(s.postComments)
+	if len(desc) > maxStatusDescriptionLength {
+		desc = desc[:maxStatusDescriptionLength]
+	}
+	return &Status{
+		Name:        name,
+		Failed:      StatusShouldFail(s.postComments, s.failLevel),
+		Description: desc,
+	}
+}
+
+// StatusShouldFail returns true if any of the given comments meets the given
+// fail level. Commit status reporters use it to decide the status state.
+// FailLevelDefault is treated as FailLevelAny so that a status reporter
+// reports failure for any finding unless -fail-level is set explicitly.
+func StatusShouldFail(cs []*reviewdog.Comment, failLevel reviewdog.FailLevel) bool {
+	if failLevel == reviewdog.FailLevelDefault {
+		failLevel = reviewdog.FailLevelAny
+	}
+	for _, c := range cs {
+		if failLevel.ShouldFail(c.Result.Diagnostic.GetSeverity()) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusDescription returns a short summary of the given comments for commit
+// status descriptions. e.g. "3 findings (1 error, 2 warnings)".
+func StatusDescription(cs []*reviewdog.Comment) string {
+	if len(cs) == 0 {
+		return "No findings"
+	}
+	counts := make(map[rdf.Severity]int)
+	for _, c := range cs {
+		counts[c.Result.Diagnostic.GetSeverity()]++
+	}
+	var details []string
+	for _, s := range []struct {
+		severity rdf.Severity
+		name     string
+	}{
+		{rdf.Severity_ERROR, "error"},
+		{rdf.Severity_WARNING, "warning"},
+		{rdf.Severity_INFO, "info"},
+	} {
+		if n := counts[s.severity]; n > 0 {
+			details = append(details, pluralize(n, s.name))
+		}
+	}
+	desc := pluralize(len(cs), "finding")
+	if len(details) > 0 {
+		desc += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+	return desc
+}
+
+func pluralize(n int, word string) string {
+	if n == 1 || word == "info" {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -0,0 +1,109 @@
+package commentutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCommitStatus_FlushStatus(t *testing.T) {
+	newComment := func(severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{Message: "test message", Severity: severity},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		toolName  string
+		failLevel reviewdog.FailLevel
+		comments  []*reviewdog.Comment
+		want      *Status
+	}{
+		{
+			name:     "no findings",
+			toolName: "golint",
+			want:     &Status{Name: "golint", Description: "No findings"},
+		},
+		{
+			name:     "default fail level fails with any finding",
+			toolName: "golint",
+			comments: []*reviewdog.Comment{newComment(rdf.Severity_INFO)},
+			want:     &Status{Name: "golint", Failed: true, Description: "1 finding (1 info)"},
+		},
+		{
+			name:      "findings below fail level",
+			failLevel: reviewdog.FailLevelError,
+			comments: []*reviewdog.Comment{
+				newComment(rdf.Severity_WARNING),
+				newComment(rdf.Severity_WARNING),
+			},
+			want: &Status{Name: "reviewdog", Description: "2 findings (2 warnings)"},
+		},
+		{
+			name:      "findings meet fail level",
+			toolName:  "govet",
+			failLevel: reviewdog.FailLevelWarning,
+			comments: []*reviewdog.Comment{
+				newComment(rdf.Severity_ERROR),
+				newComment(rdf.Severity_WARNING),
+				newComment(rdf.Severity_UNKNOWN_SEVERITY),
+			},
+			want: &Status{Name: "govet", Failed: true, Description: "3 findings (1 error, 1 warning)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCommitStatus(tt.toolName, tt.failLevel)
+			for _, c := range tt.comments {
+				if err := s.Post(context.Background(), c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			called := 0
+			if err := s.FlushStatus(context.Background(), func(_ context.Context, got *Status) error {
+				called++
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("status (-want +got):\n%s", diff)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if called != 1 {
+				t.Errorf("set called %d times, want once", called)
+			}
+		})
+	}
+}
+
+func TestCommitStatus_FlushStatus_perTool(t *testing.T) {
+	ctx := context.Background()
+	s := NewCommitStatus("golint", reviewdog.FailLevelDefault)
+	s.Post(ctx, &reviewdog.Comment{Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{}}})
+	errAPI := errors.New("API error")
+	if err := s.FlushStatus(ctx, func(context.Context, *Status) error { return errAPI }); !errors.Is(err, errAPI) {
+		t.Errorf("got %v, want %v", err, errAPI)
+	}
+
+	// Comments of the previous tool are cleared even if the API call failed.
+	s.SetTool("govet", "")
+	if err := s.FlushStatus(ctx, func(_ context.Context, got *Status) error {
+		want := &Status{Name: "govet", Description: "No findings"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("status (-want +got):\n%s", diff)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
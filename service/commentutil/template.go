@@ -0,0 +1,76 @@
+package commentutil
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// TemplateData is data passed to a comment body template.
+type TemplateData struct {
+	// Diagnostic is the reported diagnostic as it is.
+	Diagnostic *rdf.Diagnostic
+	Message    string
+	// Severity is a severity name (ERROR, WARNING, INFO) or empty.
+	Severity string
+	// SeverityIcon is an emoji of the severity used in default comments.
+	SeverityIcon string
+	ToolName     string
+	Code         string
+	CodeURL      string
+	Path         string
+	Line         int
+	// InDiffContext is true if the diagnostic is in diff context. Suggestions
+	// are posted only in diff context.
+	InDiffContext    bool
+	Suggestions      []*rdf.Suggestion
+	RelatedLocations []*rdf.RelatedLocation
+	// BodyPrefix is "reported by reviewdog" text used in default comments.
+	BodyPrefix string
+}
+
+// ParseTemplate parses a comment body template. See TemplateData for
+// available data.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comment template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// NewTemplateData returns data passed to a comment body template.
+func NewTemplateData(c *reviewdog.Comment) *TemplateData {
+	d := c.Result.Diagnostic
+	data := &TemplateData{
+		Diagnostic:       d,
+		Message:          d.GetMessage(),
+		SeverityIcon:     severity(c),
+		ToolName:         toolName(c),
+		Code:             d.GetCode().GetValue(),
+		CodeURL:          d.GetCode().GetUrl(),
+		Path:             d.GetLocation().GetPath(),
+		Line:             int(d.GetLocation().GetRange().GetStart().GetLine()),
+		InDiffContext:    c.Result.InDiffContext,
+		Suggestions:      d.GetSuggestions(),
+		RelatedLocations: d.GetRelatedLocations(),
+		BodyPrefix:       BodyPrefix,
+	}
+	if s := d.GetSeverity(); s != rdf.Severity_UNKNOWN_SEVERITY {
+		data.Severity = s.String()
+	}
+	return data
+}
+
+func executeTemplate(c *reviewdog.Comment) (string, bool) {
+	var sb strings.Builder
+	if err := c.Template.Execute(&sb, NewTemplateData(c)); err != nil {
+		log.Printf("reviewdog: failed to execute comment template, fallback to the default: %v", err)
+		return "", false
+	}
+	return sb.String(), true
+}
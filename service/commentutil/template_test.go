@@ -0,0 +1,69 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestMarkdownComment_template(t *testing.T) {
+	newComment := func(tmpl string) *reviewdog.Comment {
+		c := &reviewdog.Comment{
+			ToolName: "tool-name",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Message:  "test message",
+					Severity: rdf.Severity_ERROR,
+					Location: &rdf.Location{
+						Path:  "reviewdog.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Code: &rdf.Code{Value: "CODE14", Url: "https://example.com/rules/CODE14"},
+					Suggestions: []*rdf.Suggestion{
+						{Text: "fixed"},
+					},
+				},
+				InDiffContext: true,
+			},
+		}
+		var err error
+		c.Template, err = ParseTemplate("test", tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{
+			tmpl: `{{.SeverityIcon}} [{{.Code}}]({{.CodeURL}}) {{.Message}} ({{.ToolName}}, {{.Severity}})`,
+			want: `🚫 [CODE14](https://example.com/rules/CODE14) test message (tool-name, ERROR)`,
+		},
+		{
+			tmpl: `{{.Message}} at {{.Path}}:{{.Line}}{{if .InDiffContext}} ({{len .Suggestions}} suggestion){{end}}` +
+				"\n\nAdd `// nolint:{{.Diagnostic.GetCode.GetValue}}` to suppress it.",
+			want: "test message at reviewdog.go:14 (1 suggestion)\n\nAdd `// nolint:CODE14` to suppress it.",
+		},
+		{
+			// Fallback to the default on execution error.
+			tmpl: `{{.Unknown}}`,
+			want: "🚫 **[tool-name]** <[CODE14](https://example.com/rules/CODE14)> " + BodyPrefix + "test message",
+		},
+	}
+	for _, tt := range tests {
+		if got := MarkdownComment(newComment(tt.tmpl)); got != tt.want {
+			t.Errorf("MarkdownComment() with template %q:\ngot:  %q\nwant: %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestParseTemplate_error(t *testing.T) {
+	if _, err := ParseTemplate("test", `{{.Message`); err == nil {
+		t.Error("want parse error")
+	}
+}
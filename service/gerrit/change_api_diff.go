@@ -0,0 +1,255 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+// Number of context lines of each hunk. Same as the default of git diff.
+// Gerrit returns this many context lines around changes and skip markers for
+// the other unchanged lines, so hunks are split at the skip markers.
+const apiDiffContextLines = 3
+
+var _ reviewdog.DiffService = &ChangeAPIDiff{}
+
+// ChangeAPIDiff is a diff service for Gerrit changes which builds a unified
+// diff with Gerrit REST API instead of local git command, so that it works in
+// shallow or sparse checkouts.
+//
+// API:
+//
+//	https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#list-files
+//	GET /changes/{change-id}/revisions/{revision-id}/files
+//	https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#get-diff
+//	GET /changes/{change-id}/revisions/{revision-id}/files/{file-id}/diff
+type ChangeAPIDiff struct {
+	cli        *RESTClient
+	changeID   string
+	revisionID string
+	// base is a patch set number to compare the revision against. The diff
+	// is against the parent commit of the revision if it's empty.
+	base string
+}
+
+// NewChangeAPIDiff returns a new ChangeAPIDiff service. base is optional patch
+// set number to diff against (e.g. previous patch set). The diff is against the
+// parent commit of the revision if base is empty.
+func NewChangeAPIDiff(cli *RESTClient, changeID, revisionID, base string) *ChangeAPIDiff {
+	return &ChangeAPIDiff{
+		cli:        cli,
+		changeID:   changeID,
+		revisionID: revisionID,
+		base:       base,
+	}
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#file-info
+type fileInfo struct {
+	Status  string `json:"status"`
+	Binary  bool   `json:"binary"`
+	OldPath string `json:"old_path"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#diff-info
+type diffInfo struct {
+	Content []diffContent `json:"content"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#diff-content
+type diffContent struct {
+	A    []string `json:"a"`
+	B    []string `json:"b"`
+	AB   []string `json:"ab"`
+	Skip int      `json:"skip"`
+}
+
+// Diff returns a unified diff of the revision.
+func (g *ChangeAPIDiff) Diff(ctx context.Context) ([]byte, error) {
+	var files map[string]*fileInfo
+	if err := g.cli.Do(ctx, "GET", g.revisionPath("/files")+g.query(), nil, &files); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for path, f := range files {
+		// Skip magic files (e.g. /COMMIT_MSG) and binary files.
+		if strings.HasPrefix(path, "/") || f.Binary {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		var d diffInfo
+		filePath := g.revisionPath("/files/" + url.PathEscape(path) + "/diff")
+		if err := g.cli.Do(ctx, "GET", filePath+g.query(fmt.Sprintf("context=%d", apiDiffContextLines)), nil, &d); err != nil {
+			return nil, fmt.Errorf("failed to get diff of %s: %w", path, err)
+		}
+		writeFileDiff(&buf, path, files[path], d.Content)
+	}
+	return buf.Bytes(), nil
+}
+
+// Strip returns 1 as a strip of the diff.
+func (g *ChangeAPIDiff) Strip() int {
+	return stripDiffResult
+}
+
+func (g *ChangeAPIDiff) revisionPath(path string) string {
+	return fmt.Sprintf("/changes/%s/revisions/%s%s", g.changeID, g.revisionID, path)
+}
+
+func (g *ChangeAPIDiff) query(params ...string) string {
+	if g.base != "" {
+		params = append(params, "base="+url.QueryEscape(g.base))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+type apiDiffLine struct {
+	op      byte // ' ', '-' or '+'
+	text    string
+	oldLine int
+	newLine int
+}
+
+func writeFileDiff(buf *bytes.Buffer, path string, f *fileInfo, content []diffContent) {
+	oldPath := path
+	if f.OldPath != "" {
+		oldPath = f.OldPath
+	}
+	lines := diffLines(content)
+	hunks := splitHunks(lines)
+	if len(hunks) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "diff --git a/%s b/%s\n", oldPath, path)
+	if f.Status == "A" {
+		buf.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(buf, "--- a/%s\n", oldPath)
+	}
+	if f.Status == "D" {
+		buf.WriteString("+++ /dev/null\n")
+	} else {
+		fmt.Fprintf(buf, "+++ b/%s\n", path)
+	}
+	for _, h := range hunks {
+		writeHunk(buf, h)
+	}
+}
+
+// diffLines flattens Gerrit diff content into lines with line numbers.
+func diffLines(content []diffContent) []apiDiffLine {
+	var lines []apiDiffLine
+	oldLine, newLine := 1, 1
+	for _, c := range content {
+		for _, l := range c.AB {
+			lines = append(lines, apiDiffLine{op: ' ', text: l, oldLine: oldLine, newLine: newLine})
+			oldLine++
+			newLine++
+		}
+		for _, l := range c.A {
+			lines = append(lines, apiDiffLine{op: '-', text: l, oldLine: oldLine, newLine: newLine})
+			oldLine++
+		}
+		for _, l := range c.B {
+			lines = append(lines, apiDiffLine{op: '+', text: l, oldLine: oldLine, newLine: newLine})
+			newLine++
+		}
+		oldLine += c.Skip
+		newLine += c.Skip
+	}
+	return lines
+}
+
+// splitHunks groups changed lines with surrounding context lines into hunks.
+// Context lines are never extended over skipped lines.
+func splitHunks(lines []apiDiffLine) [][]apiDiffLine {
+	var hunks [][]apiDiffLine
+	start, end := -1, -1 // [start, end) of the current hunk.
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		if start != -1 && i-end > 2*apiDiffContextLines || !contiguous(lines, end, i) {
+			hunks = append(hunks, lines[start:extendContext(lines, end, 1)])
+			start = -1
+		}
+		if start == -1 {
+			start = extendContext(lines, i, -1)
+		}
+		end = i + 1
+	}
+	if start != -1 {
+		hunks = append(hunks, lines[start:extendContext(lines, end, 1)])
+	}
+	return hunks
+}
+
+// extendContext returns the index extended from i by context lines toward the
+// given direction.
+func extendContext(lines []apiDiffLine, i, dir int) int {
+	for n := 0; n < apiDiffContextLines; n++ {
+		next := i + dir
+		if dir < 0 && (next < 0 || lines[next].op != ' ' || !contiguous(lines, next+1, i+1)) {
+			break
+		}
+		if dir > 0 && (i >= len(lines) || lines[i].op != ' ' || !contiguous(lines, i, i+1)) {
+			break
+		}
+		i = next
+	}
+	return i
+}
+
+// contiguous returns true if there are no skipped lines between lines[i-1]
+// and lines[j-1].
+func contiguous(lines []apiDiffLine, i, j int) bool {
+	if i <= 0 || j > len(lines) || i >= j {
+		return true
+	}
+	for k := i; k < j; k++ {
+		prev, cur := lines[k-1], lines[k]
+		if cur.oldLine-prev.oldLine > 1 || cur.newLine-prev.newLine > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func writeHunk(buf *bytes.Buffer, h []apiDiffLine) {
+	var oldCount, newCount int
+	for _, l := range h {
+		if l.op != '+' {
+			oldCount++
+		}
+		if l.op != '-' {
+			newCount++
+		}
+	}
+	oldStart, newStart := h[0].oldLine, h[0].newLine
+	// A hunk start is the line before the hunk if the range is empty.
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, l := range h {
+		buf.WriteByte(l.op)
+		buf.WriteString(l.text)
+		buf.WriteByte('\n')
+	}
+}
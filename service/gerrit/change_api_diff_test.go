@@ -0,0 +1,147 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/diff"
+)
+
+func TestChangeAPIDiff_Diff(t *testing.T) {
+	files := map[string]*fileInfo{
+		"/COMMIT_MSG": {Status: "A"},
+		"a.go":        {Status: "M"},
+		"new.go":      {Status: "A"},
+		"old.go":      {Status: "D"},
+		"renamed.go":  {Status: "R", OldPath: "orig.go"},
+		"image.png":   {Status: "M", Binary: true},
+	}
+	diffs := map[string]diffInfo{
+		"a.go": {Content: []diffContent{
+			{Skip: 2},
+			{AB: []string{"3", "4", "5"}},
+			{A: []string{"6"}, B: []string{"six"}},
+			{AB: []string{"7", "8", "9"}},
+			{Skip: 4},
+			{AB: []string{"14", "15", "16"}},
+			{B: []string{"16.5"}},
+			{AB: []string{"17", "18"}},
+			{Skip: 10},
+		}},
+		"new.go":     {Content: []diffContent{{B: []string{"package a"}}}},
+		"old.go":     {Content: []diffContent{{A: []string{"package a", ""}}}},
+		"renamed.go": {Content: []diffContent{{AB: []string{"package a"}}}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/changes/changeID/revisions/rev/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		if got := r.URL.Query().Get("base"); got != "1" {
+			t.Errorf("base = %q, want 1", got)
+		}
+		fmt.Fprint(w, ")]}'\n")
+		if err := json.NewEncoder(w).Encode(files); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.HandleFunc("/changes/changeID/revisions/rev/files/{path}/diff", func(w http.ResponseWriter, r *http.Request) {
+		path := r.PathValue("path")
+		d, ok := diffs[path]
+		if !ok {
+			t.Errorf("unexpected diff request: %v", r.URL)
+		}
+		if got := r.URL.Query().Get("context"); got != "3" {
+			t.Errorf("context = %q, want 3", got)
+		}
+		if got := r.URL.Query().Get("base"); got != "1" {
+			t.Errorf("base = %q, want 1", got)
+		}
+		fmt.Fprint(w, ")]}'\n")
+		if err := json.NewEncoder(w).Encode(d); err != nil {
+			t.Error(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g := NewChangeAPIDiff(NewRESTClient(ts.URL, nil), "changeID", "rev", "1")
+	got, err := g.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,7 +3,7 @@
+ 3
+ 4
+ 5
+-6
++six
+ 7
+ 8
+ 9
+@@ -14,5 +14,6 @@
+ 14
+ 15
+ 16
++16.5
+ 17
+ 18
+diff --git a/new.go b/new.go
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,1 @@
++package a
+diff --git a/old.go b/old.go
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package a
+-
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("diff has diff:\n%s", diff)
+	}
+	if g.Strip() != 1 {
+		t.Errorf("Strip() = %d, want 1", g.Strip())
+	}
+
+	fds, err := diff.ParseMultiFile(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fds) != 3 {
+		t.Errorf("parsed %d file diffs, want 3", len(fds))
+	}
+}
+
+func TestChangeAPIDiff_Diff_parentBase(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/changes/changeID/revisions/rev/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("unexpected query: %v", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, ")]}'\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g := NewChangeAPIDiff(NewRESTClient(ts.URL, nil), "changeID", "rev", "")
+	got, err := g.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got diff %q, want empty", got)
+	}
+}
@@ -2,14 +2,28 @@ package gerrit
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"unicode/utf16"
 
 	"golang.org/x/build/gerrit"
 
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 var _ reviewdog.CommentService = &ChangeReviewCommenter{}
+var _ reviewdog.NamedCommentService = &ChangeReviewCommenter{}
+var _ reviewdog.FinishCommentService = &ChangeReviewCommenter{}
+
+const outdatedCommentMessage = "This finding is no longer reported by reviewdog."
 
 // ChangeReviewCommenter is a comment service for Gerrit Change Review
 // API:
@@ -18,11 +32,35 @@ var _ reviewdog.CommentService = &ChangeReviewCommenter{}
 //	POST /changes/{change-id}/revisions/{revision-id}/review
 type ChangeReviewCommenter struct {
 	cli        *gerrit.Client
+	rest       *RESTClient
 	changeID   string
 	revisionID string
+	toolName   string
+
+	// robotRunID is set only when robot comments are enabled.
+	robotComments bool
+	robotRunID    string
+
+	// label and failLevel are set only when label voting is enabled.
+	label     string
+	failLevel reviewdog.FailLevel
+	// failed is true once any tool has findings which meet failLevel.
+	failed bool
+	// summaries holds the summary message of each flushed tool. The vote is
+	// posted once with them by Finish.
+	summaries []string
 
 	muComments   sync.Mutex
 	postComments []*reviewdog.Comment
+
+	// patchSetURL is the URL of the patch set in Gerrit web UI, used to link
+	// related locations.
+	patchSetURL string
+
+	postedFingerprints map[string]bool
+	// outdatedComments holds the latest comment of unresolved threads
+	// previously posted by the current tool. Keyed by fingerprint.
+	outdatedComments map[string][]*commentInfo
 }
 
 // NewChangeReviewCommenter returns a new NewChangeReviewCommenter service.
@@ -36,6 +74,36 @@ func NewChangeReviewCommenter(cli *gerrit.Client, changeID, revisionID string) *
 	}
 }
 
+// SetRESTClient sets the client for APIs which golang.org/x/build/gerrit
+// doesn't support. It's required for comment ranges, robot comments, links to
+// related locations and resolving outdated comments. Without it, comments are
+// posted to their lines without ranges.
+func (g *ChangeReviewCommenter) SetRESTClient(rest *RESTClient) {
+	g.rest = rest
+}
+
+// EnableRobotComments makes ChangeReviewCommenter post robot comments with fix
+// suggestions instead of plain comments. runID is used as robot_run_id of the
+// comments. It requires SetRESTClient.
+func (g *ChangeReviewCommenter) EnableRobotComments(runID string) {
+	g.robotComments = true
+	g.robotRunID = runID
+}
+
+// EnableLabelVote makes ChangeReviewCommenter vote on the given label (e.g.
+// "Verified") along with a summary message of all tools when Finish is called.
+// It votes -1 if findings of any tool meet the given fail level (any finding
+// if it's FailLevelDefault), otherwise +1.
+func (g *ChangeReviewCommenter) EnableLabelVote(label string, failLevel reviewdog.FailLevel) {
+	g.label = label
+	g.failLevel = failLevel
+}
+
+// SetTool sets the tool name used in the summary message and meta comments.
+func (g *ChangeReviewCommenter) SetTool(toolName string, _ string) {
+	g.toolName = toolName
+}
+
 // Post accepts a comment and holds it. Flush method actually posts comments to Gerrit
 func (g *ChangeReviewCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
 	g.muComments.Lock()
@@ -46,30 +114,525 @@ func (g *ChangeReviewCommenter) Post(_ context.Context, c *reviewdog.Comment) er
 
 func (*ChangeReviewCommenter) ShouldPrependGitRelDir() bool { return true }
 
-// Flush posts comments which has not been posted yet.
+// Flush posts comments which has not been posted yet, and resolves comments
+// whose findings are no longer reported.
 func (g *ChangeReviewCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 	defer func() { g.postComments = nil }()
 
-	return g.postAllComments(ctx)
+	if g.robotComments && g.rest == nil {
+		return errors.New("robot comments require a REST client (SetRESTClient)")
+	}
+	g.addSummary()
+	if err := g.setPostedComment(ctx); err != nil {
+		return err
+	}
+	g.setPatchSetURL(ctx)
+	var err error
+	if g.robotComments {
+		err = g.postAllRobotComments(ctx)
+	} else {
+		err = g.postAllComments(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return g.resolveOutdatedComments(ctx)
 }
 
 func (g *ChangeReviewCommenter) postAllComments(ctx context.Context) error {
-	review := gerrit.ReviewInput{
-		Comments: map[string][]gerrit.CommentInput{},
+	review := reviewInput{
+		Comments: map[string][]commentInput{},
 	}
 	for _, c := range g.postComments {
 		if !c.Result.InDiffFile {
 			continue
 		}
+		fprint, posted, err := g.checkPosted(c)
+		if err != nil {
+			return err
+		}
+		if posted {
+			continue
+		}
 		loc := c.Result.Diagnostic.GetLocation()
 		path := loc.GetPath()
-		review.Comments[path] = append(review.Comments[path], gerrit.CommentInput{
-			Line:    int(loc.GetRange().GetStart().GetLine()),
-			Message: c.Result.Diagnostic.GetMessage(),
+		line, rng := commentLocation(loc, c.Result.SourceLines)
+		review.Comments[path] = append(review.Comments[path], commentInput{
+			Line:       line,
+			Range:      rng,
+			Message:    g.buildMessage(c, fprint),
+			Unresolved: boolPtr(true),
+		})
+	}
+
+	if g.rest == nil {
+		return g.cli.SetReview(ctx, g.changeID, g.revisionID, review.withoutRanges())
+	}
+	// gerrit.CommentInput doesn't support comment ranges.
+	return g.rest.Do(ctx, "POST", fmt.Sprintf("/changes/%s/revisions/%s/review", g.changeID, g.revisionID), review, nil)
+}
+
+// checkPosted returns the fingerprint of the given comment and whether it has
+// been posted already. It also marks the posted comment as non-outdated.
+func (g *ChangeReviewCommenter) checkPosted(c *reviewdog.Comment) (string, bool, error) {
+	fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
+	if err != nil {
+		return "", false, err
+	}
+	delete(g.outdatedComments, fprint)
+	return fprint, g.postedFingerprints[fprint], nil
+}
+
+func (g *ChangeReviewCommenter) buildMessage(c *reviewdog.Comment, fprint string) string {
+	msg := c.Result.Diagnostic.GetMessage()
+	if related := g.relatedLocations(c); related != "" {
+		msg += "\n\n" + related
+	}
+	return msg + "\n\n" + serviceutil.BuildMetaComment(fprint, g.toolName)
+}
+
+// relatedLocations renders related locations as a list. Each location links
+// to the file of the patch set in Gerrit web UI if possible.
+func (g *ChangeReviewCommenter) relatedLocations(c *reviewdog.Comment) string {
+	var sb strings.Builder
+	for _, rel := range c.Result.Diagnostic.GetRelatedLocations() {
+		loc := rel.GetLocation()
+		if loc.GetPath() == "" {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("Related locations:")
+		}
+		sb.WriteString("\n* ")
+		sb.WriteString(loc.GetPath())
+		line := loc.GetRange().GetStart().GetLine()
+		if line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", line))
+		}
+		if msg := rel.GetMessage(); msg != "" {
+			sb.WriteString(": " + msg)
+		}
+		if g.patchSetURL != "" {
+			u := g.patchSetURL + "/" + loc.GetPath()
+			if line > 0 {
+				u += fmt.Sprintf("#%d", line)
+			}
+			sb.WriteString("\n  " + u)
+		}
+	}
+	return sb.String()
+}
+
+// setPatchSetURL sets the URL of the patch set (e.g.
+// https://review.example.com/c/project/+/123/4) if any comment has related
+// locations.
+func (g *ChangeReviewCommenter) setPatchSetURL(ctx context.Context) {
+	if g.rest == nil || g.patchSetURL != "" || !hasRelatedLocations(g.postComments) {
+		return
+	}
+	change, err := g.cli.GetChange(ctx, g.changeID, gerrit.QueryChangesOpt{Fields: []string{"ALL_REVISIONS"}})
+	if err != nil {
+		log.Printf("reviewdog: failed to get the change to link related locations: %v", err)
+		return
+	}
+	ps, err := strconv.Atoi(g.revisionID)
+	if err != nil {
+		rev := g.revisionID
+		if rev == "current" {
+			rev = change.CurrentRevision
+		}
+		r, ok := change.Revisions[rev]
+		if !ok {
+			return
+		}
+		ps = r.PatchSetNumber
+	}
+	g.patchSetURL = fmt.Sprintf("%s/c/%s/+/%d/%d", g.rest.url, change.Project, change.ChangeNumber, ps)
+}
+
+func hasRelatedLocations(cs []*reviewdog.Comment) bool {
+	for _, c := range cs {
+		if len(c.Result.Diagnostic.GetRelatedLocations()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// addSummary records the result of the current tool for the label vote.
+func (g *ChangeReviewCommenter) addSummary() {
+	if g.label == "" {
+		return
+	}
+	g.failed = g.failed || commentutil.StatusShouldFail(g.postComments, g.failLevel)
+	toolName := g.toolName
+	if toolName == "" {
+		toolName = "reviewdog"
+	}
+	g.summaries = append(g.summaries, fmt.Sprintf("[%s] %s", toolName, commentutil.StatusDescription(g.postComments)))
+}
+
+// Finish votes on the label once with the summary of all flushed tools if
+// label voting is enabled.
+func (g *ChangeReviewCommenter) Finish(ctx context.Context) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	if g.label == "" || len(g.summaries) == 0 {
+		return nil
+	}
+	value := 1
+	if g.failed {
+		value = -1
+	}
+	review := gerrit.ReviewInput{
+		Message: strings.Join(g.summaries, "\n"),
+		Labels:  map[string]int{g.label: value},
+	}
+	if err := g.cli.SetReview(ctx, g.changeID, g.revisionID, review); err != nil {
+		return fmt.Errorf("failed to vote on %s: %w", g.label, err)
+	}
+	g.summaries = nil
+	return nil
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-info
+type commentInfo struct {
+	ID         string `json:"id"`
+	PatchSet   int    `json:"patch_set"`
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	Message    string `json:"message"`
+	InReplyTo  string `json:"in_reply_to"`
+	Updated    string `json:"updated"`
+	Unresolved *bool  `json:"unresolved"`
+}
+
+// setPostedComment gets comments and robot comments posted to the change.
+func (g *ChangeReviewCommenter) setPostedComment(ctx context.Context) error {
+	g.postedFingerprints = make(map[string]bool)
+	g.outdatedComments = make(map[string][]*commentInfo)
+
+	cs, err := g.listComments(ctx)
+	if err != nil {
+		return err
+	}
+	for root, latest := range latestCommentsInThreads(cs) {
+		meta := serviceutil.ExtractMetaComment(root.Message)
+		if meta == nil {
+			continue
+		}
+		resolved := latest.Unresolved != nil && !*latest.Unresolved
+		if resolved && latest.Message == outdatedCommentMessage {
+			// The thread was resolved by reviewdog as outdated. Post the
+			// finding again if it recurs.
+			continue
+		}
+		g.postedFingerprints[meta.GetFingerprint()] = true
+		// gerrit.CommentInfo doesn't have lines to reply to.
+		if g.rest != nil && meta.GetSourceName() == g.toolName && !resolved {
+			// Remove non-outdated comment later.
+			g.outdatedComments[meta.GetFingerprint()] = append(g.outdatedComments[meta.GetFingerprint()], latest)
+		}
+	}
+	return nil
+}
+
+// gerritTimeLayout is the layout of timestamps of Gerrit REST API.
+const gerritTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// listComments lists comments and robot comments of the change. It lists only
+// comments without their lines if the REST client isn't set.
+func (g *ChangeReviewCommenter) listComments(ctx context.Context) ([]*commentInfo, error) {
+	var cs []*commentInfo
+	if g.rest == nil {
+		m, err := g.cli.ListChangeComments(ctx, g.changeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for path, comments := range m {
+			for _, c := range comments {
+				cs = append(cs, &commentInfo{
+					ID:         c.ID,
+					PatchSet:   c.PatchSet,
+					Path:       path,
+					Message:    c.Message,
+					InReplyTo:  c.InReplyTo,
+					Updated:    c.Updated.Time().UTC().Format(gerritTimeLayout),
+					Unresolved: c.Unresolved,
+				})
+			}
+		}
+		return cs, nil
+	}
+	for _, endpoint := range []string{"comments", "robotcomments"} {
+		var m map[string][]*commentInfo
+		if err := g.rest.Do(ctx, "GET", fmt.Sprintf("/changes/%s/%s", g.changeID, endpoint), nil, &m); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", endpoint, err)
+		}
+		for path, comments := range m {
+			for _, c := range comments {
+				c.Path = path
+				cs = append(cs, c)
+			}
+		}
+	}
+	return cs, nil
+}
+
+// latestCommentsInThreads returns map of root comment to the latest comment in
+// its thread.
+func latestCommentsInThreads(cs []*commentInfo) map[*commentInfo]*commentInfo {
+	byID := make(map[string]*commentInfo, len(cs))
+	for _, c := range cs {
+		byID[c.ID] = c
+	}
+	threads := make(map[*commentInfo]*commentInfo)
+	for _, c := range cs {
+		root := c
+		for seen := 0; root.InReplyTo != "" && seen < len(cs); seen++ {
+			parent, ok := byID[root.InReplyTo]
+			if !ok {
+				break
+			}
+			root = parent
+		}
+		// Timestamps are in "yyyy-mm-dd hh:mm:ss.fffffffff" format, so they can
+		// be compared as string.
+		if latest, ok := threads[root]; !ok || latest.Updated < c.Updated {
+			threads[root] = c
+		}
+	}
+	return threads
+}
+
+// resolveOutdatedComments replies to unresolved comments whose findings are
+// no longer reported and marks them resolved. Replies are posted to the patch
+// set of the original comments.
+func (g *ChangeReviewCommenter) resolveOutdatedComments(ctx context.Context) error {
+	reviews := make(map[int]*gerrit.ReviewInput)
+	for _, cs := range g.outdatedComments {
+		for _, c := range cs {
+			review, ok := reviews[c.PatchSet]
+			if !ok {
+				review = &gerrit.ReviewInput{Comments: map[string][]gerrit.CommentInput{}}
+				reviews[c.PatchSet] = review
+			}
+			review.Comments[c.Path] = append(review.Comments[c.Path], gerrit.CommentInput{
+				Line:       c.Line,
+				Message:    outdatedCommentMessage,
+				InReplyTo:  c.ID,
+				Unresolved: boolPtr(false),
+			})
+		}
+	}
+	patchSets := make([]int, 0, len(reviews))
+	for ps := range reviews {
+		patchSets = append(patchSets, ps)
+	}
+	sort.Ints(patchSets)
+	for _, ps := range patchSets {
+		if err := g.cli.SetReview(ctx, g.changeID, strconv.Itoa(ps), *reviews[ps]); err != nil {
+			return fmt.Errorf("failed to resolve outdated comments (patch set %d): %w", ps, err)
+		}
+	}
+	return nil
+}
+
+// reviewInput is gerrit.ReviewInput with comment ranges and robot comments.
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#review-input
+type reviewInput struct {
+	Comments      map[string][]commentInput      `json:"comments,omitempty"`
+	RobotComments map[string][]robotCommentInput `json:"robot_comments,omitempty"`
+}
+
+// withoutRanges converts the review to gerrit.ReviewInput. Comment ranges are
+// dropped.
+func (r reviewInput) withoutRanges() gerrit.ReviewInput {
+	review := gerrit.ReviewInput{Comments: make(map[string][]gerrit.CommentInput, len(r.Comments))}
+	for path, cs := range r.Comments {
+		for _, c := range cs {
+			review.Comments[path] = append(review.Comments[path], gerrit.CommentInput{
+				Line:       c.Line,
+				Message:    c.Message,
+				Unresolved: c.Unresolved,
+			})
+		}
+	}
+	return review
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-input
+type commentInput struct {
+	Line       int           `json:"line,omitempty"`
+	Range      *commentRange `json:"range,omitempty"`
+	Message    string        `json:"message"`
+	Unresolved *bool         `json:"unresolved,omitempty"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#robot-comment-input
+type robotCommentInput struct {
+	Line           int                 `json:"line,omitempty"`
+	Range          *commentRange       `json:"range,omitempty"`
+	Message        string              `json:"message"`
+	RobotID        string              `json:"robot_id"`
+	RobotRunID     string              `json:"robot_run_id"`
+	URL            string              `json:"url,omitempty"`
+	FixSuggestions []fixSuggestionInfo `json:"fix_suggestions,omitempty"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#fix-suggestion-info
+type fixSuggestionInfo struct {
+	Description  string               `json:"description"`
+	Replacements []fixReplacementInfo `json:"replacements"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#fix-replacement-info
+type fixReplacementInfo struct {
+	Path        string       `json:"path"`
+	Range       commentRange `json:"range"`
+	Replacement string       `json:"replacement"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-range
+type commentRange struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+func (g *ChangeReviewCommenter) postAllRobotComments(ctx context.Context) error {
+	review := reviewInput{
+		RobotComments: map[string][]robotCommentInput{},
+	}
+	for _, c := range g.postComments {
+		if !c.Result.InDiffFile {
+			continue
+		}
+		fprint, posted, err := g.checkPosted(c)
+		if err != nil {
+			return err
+		}
+		if posted {
+			continue
+		}
+		path := c.Result.Diagnostic.GetLocation().GetPath()
+		review.RobotComments[path] = append(review.RobotComments[path], g.buildRobotComment(c, fprint))
+	}
+	return g.rest.Do(ctx, "POST", fmt.Sprintf("/changes/%s/revisions/%s/review", g.changeID, g.revisionID), review, nil)
+}
+
+func (g *ChangeReviewCommenter) buildRobotComment(c *reviewdog.Comment, fprint string) robotCommentInput {
+	d := c.Result.Diagnostic
+	robotID := d.GetSource().GetName()
+	if robotID == "" {
+		robotID = c.ToolName
+	}
+	if robotID == "" {
+		robotID = "reviewdog"
+	}
+	line, rng := commentLocation(d.GetLocation(), c.Result.SourceLines)
+	rc := robotCommentInput{
+		Line:       line,
+		Range:      rng,
+		Message:    g.buildMessage(c, fprint),
+		RobotID:    robotID,
+		RobotRunID: g.robotRunID,
+		URL:        d.GetCode().GetUrl(),
+	}
+	for _, s := range d.GetSuggestions() {
+		rc.FixSuggestions = append(rc.FixSuggestions, fixSuggestionInfo{
+			Description: fmt.Sprintf("%s suggestion", robotID),
+			Replacements: []fixReplacementInfo{
+				buildFixReplacement(d.GetLocation().GetPath(), s, c.Result.SourceLines),
+			},
 		})
 	}
+	return rc
+}
+
+// commentLocation returns the line and the range of a comment for the given
+// location. The range is nil if the location is a single line without
+// columns, or if the end of the range is unknown.
+func commentLocation(loc *rdf.Location, sourceLines map[int]string) (int, *commentRange) {
+	start := loc.GetRange().GetStart()
+	end := loc.GetRange().GetEnd()
+	startLine := int(start.GetLine())
+	endLine := int(end.GetLine())
+	if endLine == 0 {
+		endLine = startLine
+	}
+	if startLine == 0 || (endLine == startLine && end.GetColumn() == 0) {
+		return startLine, nil
+	}
+	var endCharacter int
+	if end.GetColumn() > 0 {
+		endCharacter = gerritCharacter(sourceLines, endLine, int(end.GetColumn()))
+	} else {
+		// The range covers the whole end line.
+		l, ok := sourceLines[endLine]
+		if !ok {
+			return startLine, nil
+		}
+		endCharacter = len(utf16.Encode([]rune(l)))
+	}
+	// The line of a comment with a range must be the end line of the range.
+	return endLine, &commentRange{
+		StartLine:      startLine,
+		StartCharacter: gerritCharacter(sourceLines, startLine, int(start.GetColumn())),
+		EndLine:        endLine,
+		EndCharacter:   endCharacter,
+	}
+}
+
+func buildFixReplacement(path string, s *rdf.Suggestion, sourceLines map[int]string) fixReplacementInfo {
+	start := s.GetRange().GetStart()
+	end := s.GetRange().GetEnd()
+	if end == nil {
+		end = start
+	}
+	endLine := int(end.GetLine())
+	if endLine == 0 {
+		endLine = int(start.GetLine())
+	}
+	if start.GetColumn() == 0 && end.GetColumn() == 0 {
+		// Line based suggestion. Replace whole lines including the last newline.
+		txt := s.GetText()
+		if txt != "" {
+			txt += "\n"
+		}
+		return fixReplacementInfo{
+			Path:        path,
+			Range:       commentRange{StartLine: int(start.GetLine()), EndLine: endLine + 1},
+			Replacement: txt,
+		}
+	}
+	return fixReplacementInfo{
+		Path: path,
+		Range: commentRange{
+			StartLine:      int(start.GetLine()),
+			StartCharacter: gerritCharacter(sourceLines, int(start.GetLine()), int(start.GetColumn())),
+			EndLine:        endLine,
+			EndCharacter:   gerritCharacter(sourceLines, endLine, int(end.GetColumn())),
+		},
+		Replacement: s.GetText(),
+	}
+}
+
+// gerritCharacter converts 1-based UTF-8 byte column to 0-based character
+// offset used by Gerrit, which counts UTF-16 code units. It falls back to the
+// byte offset if the source line is not available.
+func gerritCharacter(sourceLines map[int]string, line, column int) int {
+	offset := max(column-1, 0)
+	l, ok := sourceLines[line]
+	if !ok || offset > len(l) {
+		return offset
+	}
+	return len(utf16.Encode([]rune(l[:offset])))
+}
 
-	return g.cli.SetReview(ctx, g.changeID, g.revisionID, review)
+func boolPtr(b bool) *bool {
+	return &b
 }
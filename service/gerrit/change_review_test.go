@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,8 +16,41 @@ import (
 	"github.com/reviewdog/reviewdog"
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
+func wantMessage(t *testing.T, c *reviewdog.Comment, toolName string) string {
+	t.Helper()
+	fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Result.Diagnostic.GetMessage() + "\n\n" + serviceutil.BuildMetaComment(fprint, toolName)
+}
+
+// handleListComments registers handlers which return the given comments and
+// robot comments of a change.
+func handleListComments(t *testing.T, mux *http.ServeMux, changePath string, comments, robotComments map[string][]*commentInfo) {
+	t.Helper()
+	for endpoint, cs := range map[string]map[string][]*commentInfo{
+		"/comments":      comments,
+		"/robotcomments": robotComments,
+	} {
+		mux.HandleFunc(changePath+endpoint, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+			if cs == nil {
+				cs = map[string][]*commentInfo{}
+			}
+			fmt.Fprint(w, ")]}'\n")
+			if err := json.NewEncoder(w).Encode(cs); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 	cwd, _ := os.Getwd()
 	defer func(dir string) {
@@ -44,15 +78,15 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 			InDiffFile: true,
 		},
 	}
-	newLnum2 := 15
 	newComment2 := &reviewdog.Comment{
 		Result: &filter.FilteredDiagnostic{
 			Diagnostic: &rdf.Diagnostic{
 				Location: &rdf.Location{
 					Path: "file2.go",
-					Range: &rdf.Range{Start: &rdf.Position{
-						Line: int32(newLnum2),
-					}},
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 15, Column: 3},
+						End:   &rdf.Position{Line: 16, Column: 5},
+					},
 				},
 				Message: "new comment 2",
 			},
@@ -81,10 +115,11 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 	}
 
 	mux := http.NewServeMux()
+	handleListComments(t, mux, "/changes/testChangeID", nil, nil)
 	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			got := new(gerrit.ReviewInput)
+			got := new(reviewInput)
 			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
 				t.Error(err)
 			}
@@ -94,15 +129,18 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 			}
 
 			line1 := int(newComment1.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
-			want := []gerrit.CommentInput{{
-				Line: line1, Message: newComment1.Result.Diagnostic.GetMessage()}}
+			want := []commentInput{{
+				Line: line1, Message: wantMessage(t, newComment1, ""), Unresolved: boolPtr(true)}}
 			if diff := cmp.Diff(got.Comments["file.go"], want); diff != "" {
 				t.Error(diff)
 			}
 
-			line2 := int(newComment2.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
-			want = []gerrit.CommentInput{{
-				Line: line2, Message: newComment2.Result.Diagnostic.GetMessage()}}
+			want = []commentInput{{
+				Line:       16,
+				Range:      &commentRange{StartLine: 15, StartCharacter: 2, EndLine: 16, EndCharacter: 4},
+				Message:    wantMessage(t, newComment2, ""),
+				Unresolved: boolPtr(true),
+			}}
 			if diff := cmp.Diff(got.Comments["file2.go"], want); diff != "" {
 				t.Error(diff)
 			}
@@ -119,6 +157,7 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 	cli := gerrit.NewClient(ts.URL, gerrit.NoAuth)
 
 	g := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID")
+	g.SetRESTClient(NewRESTClient(ts.URL, nil))
 	for _, c := range comments {
 		if err := g.Post(ctx, c); err != nil {
 			t.Error(err)
@@ -129,3 +168,609 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 		t.Errorf("%v", err)
 	}
 }
+
+func TestChangeReviewCommenter_RobotComments(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer func(dir string) {
+		if err := os.Chdir(dir); err != nil {
+			t.Error(err)
+		}
+	}(cwd)
+	if err := os.Chdir("../.."); err != nil {
+		t.Error(err)
+	}
+
+	ctx := context.Background()
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message: "line based suggestion",
+					Code:    &rdf.Code{Value: "R1", Url: "https://example.com/R1"},
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{Start: &rdf.Position{Line: 14}, End: &rdf.Position{Line: 15}},
+							Text:  "fixed",
+						},
+					},
+				},
+				InDiffFile: true,
+			},
+		},
+		{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 15}, End: &rdf.Position{Line: 3, Column: 16}},
+					},
+					Message: "column based suggestion",
+					Source:  &rdf.Source{Name: "source"},
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 15}, End: &rdf.Position{Line: 3, Column: 16}},
+							Text:  "y",
+						},
+					},
+				},
+				InDiffFile: true,
+				SourceLines: map[int]string{
+					3: "a := \"𐐀\" + x",
+				},
+			},
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "file3.go"},
+					Message:  "comment outside diff",
+				},
+				InDiffFile: false,
+			},
+		},
+	}
+
+	apiCalled := 0
+	mux := http.NewServeMux()
+	handleListComments(t, mux, "/a/changes/testChangeID", nil, nil)
+	mux.HandleFunc(`/a/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+		apiCalled++
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("unexpected basic auth: %q, %q", user, pass)
+		}
+		got := new(reviewInput)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		want := reviewInput{
+			RobotComments: map[string][]robotCommentInput{
+				"file.go": {
+					{
+						Line:       14,
+						Message:    wantMessage(t, comments[0], ""),
+						RobotID:    "tool",
+						RobotRunID: "run-1",
+						URL:        "https://example.com/R1",
+						FixSuggestions: []fixSuggestionInfo{{
+							Description: "tool suggestion",
+							Replacements: []fixReplacementInfo{{
+								Path:        "file.go",
+								Range:       commentRange{StartLine: 14, EndLine: 16},
+								Replacement: "fixed\n",
+							}},
+						}},
+					},
+					{
+						Line:       3,
+						Range:      &commentRange{StartLine: 3, StartCharacter: 12, EndLine: 3, EndCharacter: 13},
+						Message:    wantMessage(t, comments[1], ""),
+						RobotID:    "source",
+						RobotRunID: "run-1",
+						FixSuggestions: []fixSuggestionInfo{{
+							Description: "source suggestion",
+							Replacements: []fixReplacementInfo{{
+								Path:        "file.go",
+								Range:       commentRange{StartLine: 3, StartCharacter: 12, EndLine: 3, EndCharacter: 13},
+								Replacement: "y",
+							}},
+						}},
+					},
+				},
+			},
+		}
+		if diff := cmp.Diff(*got, want); diff != "" {
+			t.Errorf("review input diff (-got +want):\n%s", diff)
+		}
+		fmt.Fprintf(w, ")]}'\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g := NewChangeReviewCommenter(gerrit.NewClient(ts.URL, gerrit.NoAuth), "testChangeID", "testRevisionID")
+	rest := NewRESTClient(ts.URL, nil)
+	rest.SetBasicAuth("user", "pass")
+	g.SetRESTClient(rest)
+	g.EnableRobotComments("run-1")
+	for _, c := range comments {
+		if err := g.Post(ctx, c); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if apiCalled != 1 {
+		t.Errorf("review API called %d times, want once", apiCalled)
+	}
+}
+
+func TestChangeReviewCommenter_LabelVote(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer func(dir string) {
+		if err := os.Chdir(dir); err != nil {
+			t.Error(err)
+		}
+	}(cwd)
+	if err := os.Chdir("../.."); err != nil {
+		t.Error(err)
+	}
+
+	newComment := func(severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message:  "comment",
+					Severity: severity,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+
+	var got []gerrit.ReviewInput
+	mux := http.NewServeMux()
+	handleListComments(t, mux, "/changes/testChangeID", nil, nil)
+	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+		review := gerrit.ReviewInput{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Error(err)
+		}
+		if review.Message != "" || review.Labels != nil {
+			got = append(got, gerrit.ReviewInput{Message: review.Message, Labels: review.Labels})
+		}
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	g := NewChangeReviewCommenter(gerrit.NewClient(ts.URL, gerrit.NoAuth), "testChangeID", "testRevisionID")
+	g.SetRESTClient(NewRESTClient(ts.URL, nil))
+	g.EnableLabelVote("Lint", reviewdog.FailLevelError)
+	for _, run := range []struct {
+		toolName string
+		comments []*reviewdog.Comment
+	}{
+		{toolName: "tool1", comments: []*reviewdog.Comment{newComment(rdf.Severity_WARNING)}},
+		{toolName: "tool2", comments: []*reviewdog.Comment{newComment(rdf.Severity_ERROR), newComment(rdf.Severity_INFO)}},
+		// The vote must not be overridden by later tools without findings.
+		{toolName: "tool3"},
+	} {
+		g.SetTool(run.toolName, "")
+		for _, c := range run.comments {
+			if err := g.Post(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 0 {
+		t.Errorf("voted before Finish: %+v", got)
+	}
+	if err := g.Finish(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gerrit.ReviewInput{
+		{
+			Message: "[tool1] 1 finding (1 warning)\n" +
+				"[tool2] 2 findings (1 error, 1 info)\n" +
+				"[tool3] No findings",
+			Labels: map[string]int{"Lint": -1},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("review input diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestChangeReviewCommenter_OutdatedComments(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer func(dir string) {
+		if err := os.Chdir(dir); err != nil {
+			t.Error(err)
+		}
+	}(cwd)
+	if err := os.Chdir("../.."); err != nil {
+		t.Error(err)
+	}
+
+	newComment := func(msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	postedComment := newComment("already posted")
+	newComment1 := newComment("new comment")
+	outdated := newComment("outdated")
+	recurring := newComment("recurring")
+	resolved := newComment("resolved")
+
+	comments := map[string][]*commentInfo{
+		"file.go": {
+			{ID: "posted", PatchSet: 1, Line: 14, Message: wantMessage(t, postedComment, "tool"), Updated: "2024-01-01 00:00:00.000000000"},
+			{ID: "outdated", PatchSet: 1, Line: 15, Message: wantMessage(t, outdated, "tool"), Updated: "2024-01-01 00:00:00.000000000", Unresolved: boolPtr(true)},
+			{ID: "outdated-reply", PatchSet: 1, Line: 15, Message: "why?", InReplyTo: "outdated", Updated: "2024-01-02 00:00:00.000000000", Unresolved: boolPtr(true)},
+			{ID: "other-tool", PatchSet: 1, Line: 16, Message: wantMessage(t, outdated, "other-tool"), Updated: "2024-01-01 00:00:00.000000000", Unresolved: boolPtr(true)},
+			{ID: "resolved", PatchSet: 1, Line: 17, Message: wantMessage(t, resolved, "tool"), Updated: "2024-01-01 00:00:00.000000000", Unresolved: boolPtr(false)},
+			// Resolved by reviewdog as outdated, but reported again.
+			{ID: "recurring", PatchSet: 1, Line: 14, Message: wantMessage(t, recurring, "tool"), Updated: "2024-01-01 00:00:00.000000000", Unresolved: boolPtr(true)},
+			{ID: "recurring-reply", PatchSet: 1, Line: 14, Message: outdatedCommentMessage, InReplyTo: "recurring", Updated: "2024-01-02 00:00:00.000000000", Unresolved: boolPtr(false)},
+		},
+	}
+
+	var gotNew, gotResolved []gerrit.CommentInput
+	mux := http.NewServeMux()
+	handleListComments(t, mux, "/changes/testChangeID", comments, nil)
+	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+		review := gerrit.ReviewInput{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Error(err)
+		}
+		gotNew = append(gotNew, review.Comments["file.go"]...)
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	mux.HandleFunc(`/changes/testChangeID/revisions/1/review`, func(w http.ResponseWriter, r *http.Request) {
+		review := gerrit.ReviewInput{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Error(err)
+		}
+		gotResolved = append(gotResolved, review.Comments["file.go"]...)
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	g := NewChangeReviewCommenter(gerrit.NewClient(ts.URL, gerrit.NoAuth), "testChangeID", "testRevisionID")
+	g.SetRESTClient(NewRESTClient(ts.URL, nil))
+	g.SetTool("tool", "")
+	for _, c := range []*reviewdog.Comment{postedComment, newComment1, recurring, resolved} {
+		if err := g.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The recurring finding is posted again, while the one resolved by a user
+	// isn't.
+	wantNew := []gerrit.CommentInput{
+		{Line: 14, Message: wantMessage(t, newComment1, "tool"), Unresolved: boolPtr(true)},
+		{Line: 14, Message: wantMessage(t, recurring, "tool"), Unresolved: boolPtr(true)},
+	}
+	if diff := cmp.Diff(gotNew, wantNew); diff != "" {
+		t.Errorf("new comments diff (-got +want):\n%s", diff)
+	}
+	wantResolved := []gerrit.CommentInput{
+		{Line: 15, Message: outdatedCommentMessage, InReplyTo: "outdated-reply", Unresolved: boolPtr(false)},
+	}
+	if diff := cmp.Diff(gotResolved, wantResolved); diff != "" {
+		t.Errorf("resolved comments diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestChangeReviewCommenter_withoutRESTClient(t *testing.T) {
+	newComment := func(msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "file.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 14, Column: 1},
+							End:   &rdf.Position{Line: 15, Column: 3},
+						},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	posted := newComment("already posted")
+	newComment1 := newComment("new comment")
+
+	var got []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /changes/testChangeID/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ")]}'\n")
+		if err := json.NewEncoder(w).Encode(map[string][]gerrit.CommentInfo{
+			"file.go": {{ID: "posted", PatchSet: 1, Message: wantMessage(t, posted, "tool")}},
+		}); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.HandleFunc("POST /changes/testChangeID/revisions/testRevisionID/review", func(w http.ResponseWriter, r *http.Request) {
+		var review struct {
+			Comments map[string][]map[string]any `json:"comments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Error(err)
+		}
+		got = append(got, review.Comments["file.go"]...)
+		fmt.Fprint(w, ")]}'\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	g := NewChangeReviewCommenter(gerrit.NewClient(ts.URL, gerrit.NoAuth), "testChangeID", "testRevisionID")
+	g.SetTool("tool", "")
+	for _, c := range []*reviewdog.Comment{posted, newComment1} {
+		if err := g.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Comments are posted to their end lines without ranges.
+	want := []map[string]any{
+		{"line": float64(15), "message": wantMessage(t, newComment1, "tool"), "unresolved": true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("comments diff (-want +got):\n%s", diff)
+	}
+
+	g.EnableRobotComments("run")
+	if err := g.Flush(ctx); err == nil {
+		t.Error("want error for robot comments without REST client")
+	}
+}
+
+func TestChangeReviewCommenter_RelatedLocations(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "sink.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: "tainted data reaches sink",
+				RelatedLocations: []*rdf.RelatedLocation{
+					{
+						Message: "source",
+						Location: &rdf.Location{
+							Path:  "source.go",
+							Range: &rdf.Range{Start: &rdf.Position{Line: 3}},
+						},
+					},
+					{
+						Location: &rdf.Location{Path: "config.go"},
+					},
+				},
+			},
+			InDiffFile: true,
+		},
+	}
+
+	var gotMessage string
+	mux := http.NewServeMux()
+	handleListComments(t, mux, "/changes/testChangeID", nil, nil)
+	mux.HandleFunc("GET /changes/testChangeID", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("o"); got != "ALL_REVISIONS" {
+			t.Errorf("o = %q, want ALL_REVISIONS", got)
+		}
+		fmt.Fprint(w, ")]}'\n")
+		fmt.Fprint(w, `{"project": "my/project", "_number": 123, "revisions": {"testRevisionID": {"_number": 4}}}`)
+	})
+	mux.HandleFunc("POST /changes/testChangeID/revisions/testRevisionID/review", func(w http.ResponseWriter, r *http.Request) {
+		got := new(gerrit.ReviewInput)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		if len(got.Comments["sink.go"]) != 1 {
+			t.Fatalf("unexpected comments: %+v", got.Comments)
+		}
+		gotMessage = got.Comments["sink.go"][0].Message
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g := NewChangeReviewCommenter(gerrit.NewClient(ts.URL, gerrit.NoAuth), "testChangeID", "testRevisionID")
+	g.SetRESTClient(NewRESTClient(ts.URL, nil))
+	if err := g.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "tainted data reaches sink\n\n" +
+		"Related locations:\n" +
+		"* source.go:3: source\n" +
+		"  " + ts.URL + "/c/my/project/+/123/4/source.go#3\n" +
+		"* config.go\n" +
+		"  " + ts.URL + "/c/my/project/+/123/4/config.go\n\n" +
+		serviceutil.BuildMetaComment(fprint, "")
+	if diff := cmp.Diff(want, gotMessage); diff != "" {
+		t.Errorf("message has diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommentLocation(t *testing.T) {
+	sourceLines := map[int]string{
+		3: "a := \"𐐀\" + x",
+		4: "b := \"𐐀\"",
+	}
+	tests := []struct {
+		name      string
+		rng       *rdf.Range
+		wantLine  int
+		wantRange *commentRange
+	}{
+		{
+			name:     "line",
+			rng:      &rdf.Range{Start: &rdf.Position{Line: 3}},
+			wantLine: 3,
+		},
+		{
+			name:     "start column only",
+			rng:      &rdf.Range{Start: &rdf.Position{Line: 3, Column: 15}},
+			wantLine: 3,
+		},
+		{
+			name:      "columns",
+			rng:       &rdf.Range{Start: &rdf.Position{Line: 3, Column: 15}, End: &rdf.Position{Line: 3, Column: 16}},
+			wantLine:  3,
+			wantRange: &commentRange{StartLine: 3, StartCharacter: 12, EndLine: 3, EndCharacter: 13},
+		},
+		{
+			name:      "end column without end line",
+			rng:       &rdf.Range{Start: &rdf.Position{Line: 3, Column: 1}, End: &rdf.Position{Column: 5}},
+			wantLine:  3,
+			wantRange: &commentRange{StartLine: 3, StartCharacter: 0, EndLine: 3, EndCharacter: 4},
+		},
+		{
+			name:      "multiple lines",
+			rng:       &rdf.Range{Start: &rdf.Position{Line: 2}, End: &rdf.Position{Line: 4}},
+			wantLine:  4,
+			wantRange: &commentRange{StartLine: 2, StartCharacter: 0, EndLine: 4, EndCharacter: 9},
+		},
+		{
+			name:     "multiple lines without source line",
+			rng:      &rdf.Range{Start: &rdf.Position{Line: 4}, End: &rdf.Position{Line: 5}},
+			wantLine: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, rng := commentLocation(&rdf.Location{Path: "file.go", Range: tt.rng}, sourceLines)
+			if line != tt.wantLine {
+				t.Errorf("line = %d, want %d", line, tt.wantLine)
+			}
+			if diff := cmp.Diff(rng, tt.wantRange); diff != "" {
+				t.Errorf("range diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_dryRun(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer func(dir string) {
+		if err := os.Chdir(dir); err != nil {
+			t.Error(err)
+		}
+	}(cwd)
+	if err := os.Chdir("../.."); err != nil {
+		t.Error(err)
+	}
+
+	mux := http.NewServeMux()
+	handleListComments(t, mux, "/changes/testChangeID", nil, nil)
+	mux.HandleFunc("/", func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	var out strings.Builder
+	dryRun := serviceutil.NewDryRunTransport(nil, &out)
+	dryRun.Response = DryRunResponse
+	cli := gerrit.NewClient(ts.URL, gerrit.NoAuth)
+	cli.HTTPClient = &http.Client{Transport: dryRun}
+
+	ctx := context.Background()
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: "comment",
+			},
+			InDiffFile: true,
+		},
+	}
+	for _, robot := range []bool{false, true} {
+		out.Reset()
+		g := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID")
+		g.SetRESTClient(NewRESTClient(ts.URL, cli.HTTPClient))
+		g.EnableLabelVote("Lint", reviewdog.FailLevelDefault)
+		if robot {
+			g.EnableRobotComments("run-1")
+		}
+		if err := g.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatalf("Flush (robot=%v): %v", robot, err)
+		}
+		if err := g.Finish(ctx); err != nil {
+			t.Fatalf("Finish (robot=%v): %v", robot, err)
+		}
+
+		var reqs []serviceutil.DryRunRequest
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			var req serviceutil.DryRunRequest
+			if err := json.Unmarshal([]byte(line), &req); err != nil {
+				t.Fatal(err)
+			}
+			reqs = append(reqs, req)
+		}
+		// Comments and the label vote.
+		if len(reqs) != 2 {
+			t.Fatalf("got %d dry-run requests, want 2 (robot=%v):\n%s", len(reqs), robot, out.String())
+		}
+		for _, req := range reqs {
+			if want := ts.URL + "/changes/testChangeID/revisions/testRevisionID/review"; req.Method != http.MethodPost || req.URL != want {
+				t.Errorf("unexpected dry-run request: %v %v", req.Method, req.URL)
+			}
+		}
+	}
+}
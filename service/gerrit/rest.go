@@ -0,0 +1,164 @@
+package gerrit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/build/gerrit"
+)
+
+// DryRunResponse is the body of fake responses to mutating requests in dry-run
+// mode (see serviceutil.DryRunTransport). JSON responses of Gerrit begin with
+// an XSSI-defeating prefix, which API clients strip.
+const DryRunResponse = ")]}'\n{}"
+
+// RESTClient calls Gerrit REST API endpoints which golang.org/x/build/gerrit
+// doesn't support yet (e.g. robot comments, comment ranges and file diffs).
+// Use gerrit.Client for other endpoints.
+//
+// It supports HTTP basic authentication and git cookie file authentication,
+// which are set by SetBasicAuth and SetGitCookieFile respectively. Requests
+// are sent anonymously if neither is set.
+type RESTClient struct {
+	url string
+	cli *http.Client
+
+	username   string
+	password   string
+	cookieFile string
+
+	cookieOnce sync.Once
+	cookie     []string
+	cookieErr  error
+}
+
+// NewRESTClient returns a new RESTClient. cli is optional.
+func NewRESTClient(url string, cli *http.Client) *RESTClient {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	return &RESTClient{
+		url: strings.TrimSuffix(url, "/"),
+		cli: cli,
+	}
+}
+
+// SetBasicAuth makes the client authenticate with HTTP basic authentication
+// (same as gerrit.BasicAuth).
+func (c *RESTClient) SetBasicAuth(username, password string) {
+	c.username = username
+	c.password = password
+}
+
+// SetGitCookieFile makes the client authenticate with cookies of the Gerrit
+// host in the given git cookie file (same as gerrit.GitCookieFileAuth).
+func (c *RESTClient) SetGitCookieFile(file string) {
+	c.cookieFile = file
+}
+
+func (c *RESTClient) anonymous() bool {
+	return c.username == "" && c.cookieFile == ""
+}
+
+// setAuth sets credentials to the request.
+func (c *RESTClient) setAuth(ctx context.Context, req *http.Request) error {
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+		return nil
+	}
+	if c.cookieFile == "" {
+		return nil
+	}
+	c.cookieOnce.Do(func() {
+		c.cookie, c.cookieErr = gitCookies(ctx, c.url, c.cookieFile)
+	})
+	if c.cookieErr != nil {
+		return c.cookieErr
+	}
+	req.Header["Cookie"] = c.cookie
+	return nil
+}
+
+// gitCookies returns Cookie headers which gerrit.GitCookieFileAuth sets for
+// the Gerrit host. As they're only accessible from gerrit.Client, it records
+// headers of a request of gerrit.Client without sending it.
+func gitCookies(ctx context.Context, url, file string) ([]string, error) {
+	rec := &cookieRecorder{}
+	probe := gerrit.NewClient(url, gerrit.GitCookieFileAuth(file))
+	probe.HTTPClient = &http.Client{Transport: rec}
+	if _, err := probe.GetAccountInfo(ctx, "self"); err != nil {
+		return nil, fmt.Errorf("failed to read git cookie file: %w", err)
+	}
+	return rec.cookie, nil
+}
+
+// cookieRecorder records Cookie headers of a request and responds an empty
+// JSON object.
+type cookieRecorder struct {
+	cookie []string
+}
+
+func (r *cookieRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.cookie = req.Header.Values("Cookie")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(DryRunResponse)),
+		Request:    req,
+	}, nil
+}
+
+// Do sends a request to the given API path (e.g. "/changes/{id}/detail"). in
+// is sent as JSON request body if it's non-nil, and the response JSON is
+// decoded into out if it's non-nil.
+func (c *RESTClient) Do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	u := c.url + path
+	if !c.anonymous() {
+		// https://gerrit-review.googlesource.com/Documentation/rest-api.html#authentication
+		u = c.url + "/a" + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := c.setAuth(ctx, req); err != nil {
+		return err
+	}
+	res, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		b, err := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return &gerrit.HTTPError{Res: res, Body: b, BodyErr: err}
+	}
+	if out == nil {
+		return nil
+	}
+	// The JSON response begins with an XSSI-defeating prefix like ")]}'".
+	// https://gerrit-review.googlesource.com/Documentation/rest-api.html#output
+	br := bufio.NewReader(res.Body)
+	if _, err := br.ReadSlice('\n'); err != nil {
+		return err
+	}
+	return json.NewDecoder(br).Decode(out)
+}
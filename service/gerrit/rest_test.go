@@ -0,0 +1,48 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRESTClient_Do_gitCookieFile(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if got := r.Header.Get("Cookie"); got != "o=secret" {
+			t.Errorf("Cookie = %q, want o=secret", got)
+		}
+		fmt.Fprint(w, ")]}'\n{\"ok\": true}")
+	}))
+	defer ts.Close()
+
+	host := strings.TrimPrefix(ts.URL, "http://")
+	host, _, _ = strings.Cut(host, ":")
+	file := filepath.Join(t.TempDir(), ".gitcookies")
+	cookie := strings.Join([]string{host, "FALSE", "/", "FALSE", "2147483647", "o", "secret"}, "\t") + "\n"
+	if err := os.WriteFile(file, []byte(cookie), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewRESTClient(ts.URL, nil)
+	c.SetGitCookieFile(file)
+	for range 2 {
+		var out struct{ OK bool }
+		if err := c.Do(context.Background(), "GET", "/changes/1/comments", nil, &out); err != nil {
+			t.Fatal(err)
+		}
+		if !out.OK {
+			t.Error("response is not decoded")
+		}
+	}
+	// Cookies are read without requests to the server.
+	if want := []string{"/a/changes/1/comments", "/a/changes/1/comments"}; strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("requested paths = %v, want %v", paths, want)
+	}
+}
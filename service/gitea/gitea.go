@@ -39,13 +39,17 @@ type PullRequest struct {
 	sha      string
 	toolName string
 
+	// failLevel is the level to submit a review as REQUEST_CHANGES. Reviews
+	// are always submitted as COMMENT if it's FailLevelDefault.
+	failLevel reviewdog.FailLevel
+
 	muComments           sync.Mutex
 	maxCommentsPerReview int
 	postComments         []*reviewdog.Comment
 
-	postedcs           commentutil.PostedComments
-	outdatedComments   map[string]*gitea.PullReviewComment // fingerprint -> comment
-	prCommentWithReply map[int64]bool                      // review id -> bool
+	postedcs         commentutil.PostedComments
+	outdatedComments map[string]*gitea.PullReviewComment // fingerprint -> comment
+	latestReplies    map[int64]*gitea.PullReviewComment  // comment id -> latest reply in the thread
 }
 
 // NewGiteaPullRequest returns a new PullRequest service.
@@ -95,10 +99,17 @@ func (g *PullRequest) SetMaxCommentsPerReview(max int) {
 	g.maxCommentsPerReview = max
 }
 
+// SetFailLevel sets the fail level. A review is submitted as REQUEST_CHANGES
+// if it contains at least 1 comment which meets the fail level.
+func (g *PullRequest) SetFailLevel(failLevel reviewdog.FailLevel) {
+	g.failLevel = failLevel
+}
+
 func (g *PullRequest) postAsReviewComment() error {
 	postComments := g.postComments
 	g.postComments = nil
 	reviewComments := make([]gitea.CreatePullReviewComment, 0, len(postComments))
+	reviewed := make([]*reviewdog.Comment, 0, len(postComments))
 	remaining := make([]*reviewdog.Comment, 0)
 	rootPath, err := serviceutil.GetGitRoot()
 	if err != nil {
@@ -118,7 +129,10 @@ func (g *PullRequest) postAsReviewComment() error {
 		}
 		if g.postedcs.IsPosted(c, giteaCommentLine(c), fprint) {
 			// it's already posted. Mark the comment as non-outdated and skip it.
-			delete(g.outdatedComments, fprint)
+			if posted, ok := g.outdatedComments[fprint]; ok {
+				g.unresolveRecurredComment(posted)
+				delete(g.outdatedComments, fprint)
+			}
 			continue
 		}
 
@@ -134,13 +148,14 @@ func (g *PullRequest) postAsReviewComment() error {
 		}
 		comment := buildReviewComment(c, buildBody(c, repoBaseHTMLURL, rootPath, fprint, g.toolName))
 		reviewComments = append(reviewComments, comment)
+		reviewed = append(reviewed, c)
 	}
 
 	if len(reviewComments) > 0 || len(remaining) > 0 {
 		// send review comments to Gitea.
 		review := gitea.CreatePullReviewOptions{
 			CommitID: g.sha,
-			State:    gitea.ReviewStateComment,
+			State:    g.reviewState(append(reviewed, remaining...)),
 			Comments: reviewComments,
 			Body:     g.remainingCommentsSummary(remaining, repoBaseHTMLURL, rootPath),
 		}
@@ -152,18 +167,83 @@ func (g *PullRequest) postAsReviewComment() error {
 	}
 
 	for _, c := range g.outdatedComments {
-		if ok := g.prCommentWithReply[c.ID]; ok {
-			// Do not remove comment with replies.
-			continue
-		}
-		if _, err := g.cli.DeleteIssueComment(g.owner, g.repo, c.ID); err != nil {
-			return fmt.Errorf("failed to delete comment (id=%d): %w", c.ID, err)
+		if err := g.resolveOutdatedComment(c); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// reviewState returns REQUEST_CHANGES if any of the given comments meets the
+// fail level, otherwise COMMENT.
+func (g *PullRequest) reviewState(cs []*reviewdog.Comment) gitea.ReviewStateType {
+	if g.failLevel != reviewdog.FailLevelDefault && commentutil.StatusShouldFail(cs, g.failLevel) {
+		return gitea.ReviewStateRequestChanges
+	}
+	return gitea.ReviewStateComment
+}
+
+// resolveOutdatedComment resolves the conversation of the comment whose
+// finding is no longer reported. It replies "fixed in <sha>" instead if the
+// Gitea server doesn't support resolving conversations via API.
+func (g *PullRequest) resolveOutdatedComment(c *gitea.PullReviewComment) error {
+	if c.Resolver != nil {
+		// Already resolved.
+		return nil
+	}
+	_, err := g.cli.ResolvePullReviewComment(g.owner, g.repo, c.ID)
+	if err == nil {
+		return nil
+	}
+	log.Printf("reviewdog: failed to resolve comment (id=%d), reply to it instead: %v", c.ID, err)
+	if reply := g.latestReplies[c.ID]; reply != nil && isFixedReply(reply.Body) {
+		// Already replied.
+		return nil
+	}
+	opt := gitea.CreatePullReviewCommentReplyOptions{Body: fixedReplyBody(g.sha)}
+	if _, _, err := g.cli.CreatePullReviewCommentReply(g.owner, g.repo, g.pr, c.ID, opt); err != nil {
+		return fmt.Errorf("failed to reply to outdated comment (id=%d): %w", c.ID, err)
+	}
+	return nil
+}
+
+// unresolveRecurredComment unresolves the conversation of the comment if it
+// was resolved as outdated by reviewdog but the finding is reported again.
+// Conversations resolved by others are left as is.
+func (g *PullRequest) unresolveRecurredComment(c *gitea.PullReviewComment) {
+	if !g.resolvedByReviewdog(c) {
+		return
+	}
+	if _, err := g.cli.UnresolvePullReviewComment(g.owner, g.repo, c.ID); err != nil {
+		log.Printf("reviewdog: failed to unresolve comment (id=%d): %v", c.ID, err)
+	}
+}
+
+// resolvedByReviewdog reports whether the conversation of the comment posted
+// by reviewdog was resolved by the same user, or after reviewdog replied that
+// the finding was fixed.
+func (g *PullRequest) resolvedByReviewdog(c *gitea.PullReviewComment) bool {
+	if c.Resolver == nil {
+		return false
+	}
+	if c.Reviewer != nil && c.Resolver.ID == c.Reviewer.ID {
+		return true
+	}
+	reply := g.latestReplies[c.ID]
+	return reply != nil && isFixedReply(reply.Body)
+}
+
+const fixedReplyPrefix = "Fixed in "
+
+func fixedReplyBody(sha string) string {
+	return fixedReplyPrefix + sha + "."
+}
+
+func isFixedReply(body string) bool {
+	return strings.HasPrefix(body, fixedReplyPrefix)
+}
+
 func buildReviewComment(c *reviewdog.Comment, body string) gitea.CreatePullReviewComment {
 	loc := c.Result.Diagnostic.GetLocation()
 
@@ -240,7 +320,7 @@ func (g *PullRequest) remainingCommentsSummary(remaining []*reviewdog.Comment, b
 func (g *PullRequest) setPostedComment() error {
 	g.postedcs = make(commentutil.PostedComments)
 	g.outdatedComments = make(map[string]*gitea.PullReviewComment)
-	g.prCommentWithReply = make(map[int64]bool)
+	g.latestReplies = make(map[int64]*gitea.PullReviewComment)
 	cs, err := g.comment()
 	if err != nil {
 		return err
@@ -249,11 +329,11 @@ func (g *PullRequest) setPostedComment() error {
 	commentThreads := make(map[string]int64, len(cs)) // commit/path:line
 	for _, c := range cs {
 		commentKey := fmt.Sprintf("%s/%s:%d", c.CommitID, c.Path, c.LineNum)
-		replyID, ok := commentThreads[commentKey]
+		rootID, ok := commentThreads[commentKey]
 		if !ok {
 			commentThreads[commentKey] = c.ID
-		} else {
-			g.prCommentWithReply[replyID] = true
+		} else if latest := g.latestReplies[rootID]; latest == nil || latest.ID < c.ID {
+			g.latestReplies[rootID] = c
 		}
 
 		if meta := serviceutil.ExtractMetaComment(c.Body); meta != nil {
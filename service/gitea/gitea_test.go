@@ -0,0 +1,179 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+func TestPullRequest_Flush_resolveOutdatedComments(t *testing.T) {
+	newComment := func(line int32, msg string, severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Message: msg,
+					Location: &rdf.Location{
+						Path:  "reviewdog.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Severity: severity,
+				},
+				InDiffFile:    true,
+				InDiffContext: true,
+				ShouldReport:  true,
+			},
+			ToolName: "tool",
+		}
+	}
+	recurred := newComment(1, "recurred", rdf.Severity_WARNING)
+	added := newComment(2, "added", rdf.Severity_ERROR)
+	resolvedByUser := newComment(3, "resolved by user", rdf.Severity_WARNING)
+	resolvedAfterReply := newComment(4, "resolved after reply", rdf.Severity_WARNING)
+	recurredFprint, err := serviceutil.Fingerprint(recurred.Result.Diagnostic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvedByUserFprint, err := serviceutil.Fingerprint(resolvedByUser.Result.Diagnostic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvedAfterReplyFprint, err := serviceutil.Fingerprint(resolvedAfterReply.Result.Diagnostic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := &gitea.User{ID: 1, UserName: "reviewdog-bot"}
+	resolver := &gitea.User{ID: 2, UserName: "haya14busa"}
+	existing := []*gitea.PullReviewComment{
+		// Resolved as outdated by reviewdog before, but reported again.
+		{ID: 10, Path: "reviewdog.go", LineNum: 1, Reviewer: bot, Resolver: bot, Body: serviceutil.BuildMetaComment(recurredFprint, "tool")},
+		// Resolved by a user. It must not be unresolved.
+		{ID: 15, Path: "reviewdog.go", LineNum: 3, Reviewer: bot, Resolver: resolver, Body: serviceutil.BuildMetaComment(resolvedByUserFprint, "tool")},
+		// Resolved by a user after reviewdog replied that it was fixed.
+		{ID: 16, Path: "reviewdog.go", LineNum: 4, Reviewer: bot, Resolver: resolver, Body: serviceutil.BuildMetaComment(resolvedAfterReplyFprint, "tool")},
+		{ID: 17, Path: "reviewdog.go", LineNum: 4, Reviewer: bot, Body: "Fixed in old-sha."},
+		// Outdated.
+		{ID: 11, Path: "reviewdog.go", LineNum: 11, Body: serviceutil.BuildMetaComment("outdated-1", "tool")},
+		// Outdated, but the server cannot resolve it.
+		{ID: 12, Path: "reviewdog.go", LineNum: 12, Body: serviceutil.BuildMetaComment("outdated-2", "tool")},
+		// Outdated, but it's already resolved.
+		{ID: 13, Path: "reviewdog.go", LineNum: 13, Resolver: resolver, Body: serviceutil.BuildMetaComment("outdated-3", "tool")},
+		// Outdated comment of another tool.
+		{ID: 14, Path: "reviewdog.go", LineNum: 14, Body: serviceutil.BuildMetaComment("outdated-4", "another-tool")},
+	}
+
+	var resolved, unresolved []string
+	replies := 0
+	reviews := 0
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	mux.HandleFunc("GET /api/v1/repos/o/r", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, gitea.Repository{HTMLURL: "https://gitea.example.com/o/r"})
+	})
+	mux.HandleFunc("GET /api/v1/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []*gitea.PullReview{{ID: 1}})
+	})
+	mux.HandleFunc("GET /api/v1/repos/o/r/pulls/14/reviews/1/comments", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, existing)
+	})
+	mux.HandleFunc("POST /api/v1/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+		reviews++
+		var req gitea.CreatePullReviewOptions
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if req.State != gitea.ReviewStateRequestChanges {
+			t.Errorf("review state = %q, want %q", req.State, gitea.ReviewStateRequestChanges)
+		}
+		if len(req.Comments) != 1 || req.Comments[0].NewLineNum != 2 {
+			t.Errorf("unexpected review comments: %+v", req.Comments)
+		}
+		writeJSON(w, gitea.PullReview{ID: 2})
+	})
+	mux.HandleFunc("POST /api/v1/repos/o/r/pulls/comments/{id}/resolve", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "12" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resolved = append(resolved, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /api/v1/repos/o/r/pulls/comments/{id}/unresolve", func(_ http.ResponseWriter, r *http.Request) {
+		unresolved = append(unresolved, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /api/v1/repos/o/r/pulls/14/comments/12/replies", func(w http.ResponseWriter, r *http.Request) {
+		replies++
+		var req gitea.CreatePullReviewCommentReplyOptions
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if want := "Fixed in sha."; req.Body != want {
+			t.Errorf("reply body = %q, want %q", req.Body, want)
+		}
+		writeJSON(w, gitea.PullReviewComment{ID: 20})
+	})
+	mux.HandleFunc("/", func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g, err := NewGiteaPullRequest(newGiteaClient(t, ts.URL), "o", "r", 14, "sha", "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetFailLevel(reviewdog.FailLevelError)
+	for _, c := range []*reviewdog.Comment{recurred, added, resolvedByUser, resolvedAfterReply} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if reviews != 1 {
+		t.Errorf("CreatePullReview API called %d times, want once", reviews)
+	}
+	if len(resolved) != 1 || resolved[0] != "11" {
+		t.Errorf("resolved comments = %v, want [11]", resolved)
+	}
+	slices.Sort(unresolved)
+	if want := []string{"10", "16"}; !slices.Equal(unresolved, want) {
+		t.Errorf("unresolved comments = %v, want %v", unresolved, want)
+	}
+	if replies != 1 {
+		t.Errorf("reply API called %d times, want once", replies)
+	}
+}
+
+func TestPullRequest_reviewState(t *testing.T) {
+	warning := &reviewdog.Comment{Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_WARNING}}}
+	tests := []struct {
+		failLevel reviewdog.FailLevel
+		want      gitea.ReviewStateType
+	}{
+		{failLevel: reviewdog.FailLevelDefault, want: gitea.ReviewStateComment},
+		{failLevel: reviewdog.FailLevelError, want: gitea.ReviewStateComment},
+		{failLevel: reviewdog.FailLevelWarning, want: gitea.ReviewStateRequestChanges},
+		{failLevel: reviewdog.FailLevelAny, want: gitea.ReviewStateRequestChanges},
+	}
+	for _, tt := range tests {
+		g := &PullRequest{failLevel: tt.failLevel}
+		if got := g.reviewState([]*reviewdog.Comment{warning}); got != tt.want {
+			t.Errorf("reviewState() with fail level %v = %q, want %q", tt.failLevel, got, tt.want)
+		}
+	}
+}
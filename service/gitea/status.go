@@ -0,0 +1,67 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+var _ reviewdog.BulkCommentService = (*Status)(nil)
+var _ reviewdog.NamedCommentService = (*Status)(nil)
+
+// Status is a CommentService which sets a Gitea commit status per tool.
+//
+// API:
+//
+//	https://try.gitea.io/api/swagger#/repository/repoCreateStatus
+//	POST /repos/:owner/:repo/statuses/:sha
+type Status struct {
+	*commentutil.CommitStatus
+
+	cli       *gitea.Client
+	owner     string
+	repo      string
+	sha       string
+	targetURL string
+}
+
+// NewGiteaStatus returns a new Status service. targetURL is optional and is
+// linked from the commit status (e.g. CI job log).
+//
+// Flush sets the context of cli for requests, so cli shouldn't be shared with
+// other services.
+func NewGiteaStatus(cli *gitea.Client, owner, repo, sha, toolName, targetURL string, failLevel reviewdog.FailLevel) *Status {
+	return &Status{
+		CommitStatus: commentutil.NewCommitStatus(toolName, failLevel),
+		cli:          cli,
+		owner:        owner,
+		repo:         repo,
+		sha:          sha,
+		targetURL:    targetURL,
+	}
+}
+
+// Flush sets a commit status for the current tool.
+func (s *Status) Flush(ctx context.Context) error {
+	return s.FlushStatus(ctx, func(ctx context.Context, st *commentutil.Status) error {
+		state := gitea.StatusSuccess
+		if st.Failed {
+			state = gitea.StatusFailure
+		}
+		// Gitea SDK uses the context of the client for requests.
+		s.cli.SetContext(ctx)
+		if _, _, err := s.cli.CreateStatus(s.owner, s.repo, s.sha, gitea.CreateStatusOption{
+			State:       state,
+			TargetURL:   s.targetURL,
+			Description: st.Description,
+			Context:     st.Name,
+		}); err != nil {
+			return fmt.Errorf("failed to create commit status (context=%s): %w", st.Name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// newGiteaClient creates a Gitea client for testing purposes.
+func newGiteaClient(t *testing.T, serverURL string) *gitea.Client {
+	t.Helper()
+	// Set version explicitly to skip the server version request.
+	cli, err := gitea.NewClient(serverURL, gitea.SetGiteaVersion("1.22.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestStatus_Flush(t *testing.T) {
+	var got gitea.CreateStatusOption
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/haya14busa/reviewdog/statuses/1414", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(gitea.Status{}); err != nil {
+			t.Error(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	s := NewGiteaStatus(newGiteaClient(t, ts.URL), "haya14busa", "reviewdog", "1414", "govet", "https://example.com/job/1", reviewdog.FailLevelError)
+	if err := s.Post(ctx, &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "test message", Severity: rdf.Severity_WARNING},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := gitea.CreateStatusOption{
+		State:       gitea.StatusSuccess,
+		Description: "1 finding (1 warning)",
+		Context:     "govet",
+		TargetURL:   "https://example.com/job/1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("status (-want +got):\n%s", diff)
+	}
+
+	// Flush uses the given context.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.Flush(canceled); err == nil {
+		t.Error("want error with canceled context")
+	}
+}
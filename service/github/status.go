@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v90/github"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+var _ reviewdog.BulkCommentService = (*Status)(nil)
+var _ reviewdog.NamedCommentService = (*Status)(nil)
+
+// Status is a CommentService which sets a commit status per tool. It's useful
+// when reviewdog doesn't have permission to use GitHub Check API.
+//
+// API:
+//
+//	https://docs.github.com/en/rest/commits/statuses?apiVersion=2022-11-28#create-a-commit-status
+//	POST /repos/:owner/:repo/statuses/:sha
+type Status struct {
+	*commentutil.CommitStatus
+
+	cli       *github.Client
+	owner     string
+	repo      string
+	sha       string
+	targetURL string
+}
+
+// NewGitHubStatus returns a new Status service. targetURL is optional and is
+// linked from the commit status (e.g. CI job log).
+func NewGitHubStatus(cli *github.Client, owner, repo, sha, toolName, targetURL string, failLevel reviewdog.FailLevel) *Status {
+	return &Status{
+		CommitStatus: commentutil.NewCommitStatus(toolName, failLevel),
+		cli:          cli,
+		owner:        owner,
+		repo:         repo,
+		sha:          sha,
+		targetURL:    targetURL,
+	}
+}
+
+// Flush sets a commit status for the current tool.
+func (s *Status) Flush(ctx context.Context) error {
+	return s.FlushStatus(ctx, func(ctx context.Context, st *commentutil.Status) error {
+		state := "success"
+		if st.Failed {
+			state = "failure"
+		}
+		status := github.RepoStatus{
+			State:       github.Ptr(state),
+			Description: github.Ptr(st.Description),
+			Context:     github.Ptr(st.Name),
+		}
+		if s.targetURL != "" {
+			status.TargetURL = github.Ptr(s.targetURL)
+		}
+		if _, _, err := s.cli.Repositories.CreateStatus(ctx, s.owner, s.repo, s.sha, status); err != nil {
+			return fmt.Errorf("failed to create commit status (context=%s): %w", st.Name, err)
+		}
+		return nil
+	})
+}
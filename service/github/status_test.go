@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v90/github"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestStatus_Flush(t *testing.T) {
+	var got github.RepoStatus
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/haya14busa/reviewdog/statuses/1414", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if err := json.NewEncoder(w).Encode(got); err != nil {
+			t.Error(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	s := NewGitHubStatus(newGitHubClient(t, ts.URL), "haya14busa", "reviewdog", "1414", "golint", "https://example.com/job/1", reviewdog.FailLevelDefault)
+	if err := s.Post(ctx, &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "test message", Severity: rdf.Severity_ERROR},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := github.RepoStatus{
+		State:       github.Ptr("failure"),
+		Description: github.Ptr("1 finding (1 error)"),
+		Context:     github.Ptr("golint"),
+		TargetURL:   github.Ptr("https://example.com/job/1"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("status (-want +got):\n%s", diff)
+	}
+}
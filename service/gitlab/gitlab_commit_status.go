@@ -0,0 +1,65 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+var _ reviewdog.BulkCommentService = &CommitStatus{}
+var _ reviewdog.NamedCommentService = &CommitStatus{}
+
+// CommitStatus is a comment service which sets a GitLab commit status per
+// tool. GitLab shows it as an external job of the pipeline, so it can be used
+// as a named gate of MergeRequest.
+//
+// API:
+//
+//	https://docs.gitlab.com/ee/api/commits.html#set-the-pipeline-status-of-a-commit
+//	POST /projects/:id/statuses/:sha
+type CommitStatus struct {
+	*commentutil.CommitStatus
+
+	cli       *gitlab.Client
+	sha       string
+	projects  string
+	targetURL string
+}
+
+// NewGitLabCommitStatus returns a new CommitStatus service. targetURL is
+// optional and is linked from the commit status (e.g. CI job log).
+func NewGitLabCommitStatus(cli *gitlab.Client, owner, repo, sha, toolName, targetURL string, failLevel reviewdog.FailLevel) *CommitStatus {
+	return &CommitStatus{
+		CommitStatus: commentutil.NewCommitStatus(toolName, failLevel),
+		cli:          cli,
+		sha:          sha,
+		projects:     owner + "/" + repo,
+		targetURL:    targetURL,
+	}
+}
+
+// Flush sets a commit status for the current tool.
+func (g *CommitStatus) Flush(ctx context.Context) error {
+	return g.FlushStatus(ctx, func(ctx context.Context, st *commentutil.Status) error {
+		state := gitlab.Success
+		if st.Failed {
+			state = gitlab.Failed
+		}
+		opt := &gitlab.SetCommitStatusOptions{
+			State:       state,
+			Name:        gitlab.Ptr(st.Name),
+			Description: gitlab.Ptr(st.Description),
+		}
+		if g.targetURL != "" {
+			opt.TargetURL = gitlab.Ptr(g.targetURL)
+		}
+		if _, _, err := g.cli.Commits.SetCommitStatus(g.projects, g.sha, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("failed to set commit status (name=%s): %w", st.Name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	gitlab "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestGitLabCommitStatus_Flush(t *testing.T) {
+	var got []gitlab.SetCommitStatusOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o%2Fr/statuses/sha", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		var req gitlab.SetCommitStatusOptions
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		got = append(got, req)
+		w.Write([]byte(`{"id": 1}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	g := NewGitLabCommitStatus(cli, "o", "r", "sha", "golint", "https://example.com/job/1", reviewdog.FailLevelError)
+	for _, s := range []rdf.Severity{rdf.Severity_WARNING, rdf.Severity_INFO} {
+		c := &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{Message: "warning", Severity: s},
+			},
+		}
+		if err := g.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	g.SetTool("govet", "")
+	if err := g.Post(ctx, &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "error", Severity: rdf.Severity_ERROR},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gitlab.SetCommitStatusOptions{
+		{
+			State:       gitlab.Success,
+			Name:        gitlab.Ptr("golint"),
+			TargetURL:   gitlab.Ptr("https://example.com/job/1"),
+			Description: gitlab.Ptr("2 findings (1 warning, 1 info)"),
+		},
+		{
+			State:       gitlab.Failed,
+			Name:        gitlab.Ptr("govet"),
+			TargetURL:   gitlab.Ptr("https://example.com/job/1"),
+			Description: gitlab.Ptr("1 finding (1 error)"),
+		},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("commit statuses diff: (-got +want)\n%s", diff)
+	}
+}
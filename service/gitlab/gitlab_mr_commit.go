@@ -12,9 +12,11 @@ import (
 
 	"github.com/reviewdog/reviewdog"
 	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 var _ reviewdog.CommentService = &MergeRequestCommitCommenter{}
+var _ reviewdog.NamedCommentService = &MergeRequestCommitCommenter{}
 
 // MergeRequestCommitCommenter is a comment service for GitLab MergeRequest.
 //
@@ -27,11 +29,16 @@ type MergeRequestCommitCommenter struct {
 	pr       int
 	sha      string
 	projects string
+	toolName string
 
 	muComments   sync.Mutex
 	postComments []*reviewdog.Comment
 
 	postedcs commentutil.PostedComments
+	// postedFingerprints holds fingerprints of comments posted by reviewdog to
+	// any commit of the MergeRequest, so that the same finding is not
+	// commented again on a new commit after force-push or additional commits.
+	postedFingerprints map[string]bool
 }
 
 // NewGitLabMergeRequestCommitCommenter returns a new MergeRequestCommitCommenter service.
@@ -56,6 +63,11 @@ func (g *MergeRequestCommitCommenter) Post(_ context.Context, c *reviewdog.Comme
 
 func (*MergeRequestCommitCommenter) ShouldPrependGitRelDir() bool { return true }
 
+// SetTool sets the tool name used in meta comments.
+func (g *MergeRequestCommitCommenter) SetTool(toolName string, _ string) {
+	g.toolName = toolName
+}
+
 // Flush posts comments which has not been posted yet.
 func (g *MergeRequestCommitCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
@@ -79,6 +91,14 @@ func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) e
 		if !c.Result.InDiffFile || lnum == 0 || g.postedcs.IsPosted(c, lnum, body) {
 			continue
 		}
+		fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
+		if err != nil {
+			return err
+		}
+		if g.postedFingerprints[fprint] {
+			continue
+		}
+		body += fmt.Sprintf("\n%s\n", serviceutil.BuildMetaComment(fprint, g.toolName))
 		eg.Go(func() error {
 			commitID, err := g.getLastCommitsID(loc.GetPath(), lnum)
 			if err != nil {
@@ -109,6 +129,7 @@ func (g *MergeRequestCommitCommenter) getLastCommitsID(path string, line int) (s
 
 func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) error {
 	g.postedcs = make(commentutil.PostedComments)
+	g.postedFingerprints = make(map[string]bool)
 	cs, err := g.comment(ctx)
 	if err != nil {
 		return err
@@ -119,25 +140,100 @@ func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) erro
 			// "body".
 			continue
 		}
+		if meta := serviceutil.ExtractMetaComment(c.Note); meta != nil {
+			g.postedFingerprints[meta.GetFingerprint()] = true
+			continue
+		}
+		// Comments posted by older reviewdog don't have meta comments.
 		g.postedcs.AddPostedComment(c.Path, int(c.Line), c.Note)
 	}
 	return nil
 }
 
+// comment returns comments of all commits in the MergeRequest, including head
+// commits of old versions which were force-pushed.
 func (g *MergeRequestCommitCommenter) comment(ctx context.Context) ([]*gitlab.CommitComment, error) {
-	commits, _, err := g.cli.MergeRequests.GetMergeRequestCommits(
-		g.projects, int64(g.pr), nil, gitlab.WithContext(ctx))
+	shas, err := g.commitSHAs(ctx)
 	if err != nil {
 		return nil, err
 	}
 	comments := make([]*gitlab.CommitComment, 0)
+	for _, sha := range shas {
+		opts := &gitlab.GetCommitCommentsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+		for {
+			tmpComments, resp, err := g.cli.Commits.GetCommitComments(
+				g.projects, sha, opts, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to list comments of commit %s: %w", sha, err)
+			}
+			comments = append(comments, tmpComments...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	return comments, nil
+}
+
+// commitSHAs returns SHAs of the current commits and head commits of all
+// versions of the MergeRequest without duplicates.
+func (g *MergeRequestCommitCommenter) commitSHAs(ctx context.Context) ([]string, error) {
+	commits, err := listAllMergeRequestCommits(g.cli, g.projects, g.pr, &gitlab.GetMergeRequestCommitsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	versions, err := listAllMergeRequestDiffVersions(g.cli, g.projects, g.pr, &gitlab.GetMergeRequestDiffVersionsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge request versions: %w", err)
+	}
+	var shas []string
+	seen := make(map[string]bool)
+	add := func(sha string) {
+		if sha != "" && !seen[sha] {
+			seen[sha] = true
+			shas = append(shas, sha)
+		}
+	}
 	for _, c := range commits {
-		tmpComments, _, err := g.cli.Commits.GetCommitComments(
-			g.projects, c.ID, nil, gitlab.WithContext(ctx))
+		add(c.ID)
+	}
+	for _, v := range versions {
+		add(v.HeadCommitSHA)
+	}
+	return shas, nil
+}
+
+func listAllMergeRequestCommits(cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.GetMergeRequestCommitsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Commit, error) {
+	var commits []*gitlab.Commit
+	for {
+		cs, resp, err := cli.MergeRequests.GetMergeRequestCommits(projectID, int64(mergeRequest), opts, options...)
 		if err != nil {
-			continue
+			return nil, err
+		}
+		commits = append(commits, cs...)
+		if resp.NextPage == 0 {
+			return commits, nil
 		}
-		comments = append(comments, tmpComments...)
+		opts.Page = resp.NextPage
+	}
+}
+
+func listAllMergeRequestDiffVersions(cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.GetMergeRequestDiffVersionsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestDiffVersion, error) {
+	var versions []*gitlab.MergeRequestDiffVersion
+	for {
+		vs, resp, err := cli.MergeRequests.GetMergeRequestDiffVersions(projectID, int64(mergeRequest), opts, options...)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, vs...)
+		if resp.NextPage == 0 {
+			return versions, nil
+		}
+		opts.Page = resp.NextPage
 	}
-	return comments, nil
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
@@ -22,6 +23,35 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 	defer os.Chdir(cwd)
 	os.Chdir("../..")
 
+	newComment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "notExistFile.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	// Comments are posted to non existing file path for mock test not to use
+	// last commit id of the line. If setting exists file path, sha is changed
+	// by last commit id.
+	alreadyCommented := newComment(1, "already commented")
+	commentedOnPreviousCommit := newComment(2, "commented on previous commit")
+	commentedOnForcePushedCommit := newComment(3, "commented on force-pushed commit")
+	newComment1 := newComment(14, "new comment")
+	fingerprint := func(c *reviewdog.Comment) string {
+		fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fprint
+	}
+
 	apiCalled := 0
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/commits", func(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +64,42 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 				ID:      "0123456789abcdef",
 				ShortID: "012345678",
 			},
+			{
+				ID:      "fedcba9876543210",
+				ShortID: "fedcba987",
+			},
+		}
+		if err := json.NewEncoder(w).Encode(cs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/versions", func(w http.ResponseWriter, r *http.Request) {
+		apiCalled++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		vs := []*gitlab.MergeRequestDiffVersion{
+			{ID: 2, HeadCommitSHA: "fedcba9876543210"},
+			{ID: 1, HeadCommitSHA: "forcepushed"},
+		}
+		if err := json.NewEncoder(w).Encode(vs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/repository/commits/forcepushed/comments", func(w http.ResponseWriter, r *http.Request) {
+		apiCalled++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		// Posted on the head commit of an old version which is no longer
+		// in the MergeRequest.
+		cs := []*gitlab.CommitComment{
+			{
+				Path: "notExistFile.go",
+				Line: 3,
+				Note: commentutil.MarkdownComment(commentedOnForcePushedCommit) + "\n" +
+					serviceutil.BuildMetaComment(fingerprint(commentedOnForcePushedCommit), "tool-name") + "\n",
+			},
 		}
 		if err := json.NewEncoder(w).Encode(cs); err != nil {
 			t.Fatal(err)
@@ -55,6 +121,25 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/repository/commits/fedcba9876543210/comments", func(w http.ResponseWriter, r *http.Request) {
+		apiCalled++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		// Posted on a previous commit which was force-pushed, or before
+		// additional commits.
+		cs := []*gitlab.CommitComment{
+			{
+				Path: "notExistFile.go",
+				Line: 2,
+				Note: commentutil.MarkdownComment(commentedOnPreviousCommit) + "\n" +
+					serviceutil.BuildMetaComment(fingerprint(commentedOnPreviousCommit), "tool-name") + "\n",
+			},
+		}
+		if err := json.NewEncoder(w).Encode(cs); err != nil {
+			t.Fatal(err)
+		}
+	})
 	mux.HandleFunc("/api/v4/projects/o%2Fr/repository/commits/sha/comments", func(w http.ResponseWriter, r *http.Request) {
 		apiCalled++
 		if r.Method != http.MethodPost {
@@ -67,7 +152,7 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 		want := gitlab.CommitComment{
 			Path:     "notExistFile.go",
 			Line:     14,
-			Note:     commentutil.BodyPrefix + "new comment",
+			Note:     commentutil.BodyPrefix + "new comment\n" + serviceutil.BuildMetaComment(fingerprint(newComment1), "tool-name") + "\n",
 			LineType: "new",
 		}
 		if diff := pretty.Compare(want, req); diff != "" {
@@ -86,39 +171,9 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 	}
 
 	g := NewGitLabMergeRequestCommitCommenter(cli, "o", "r", 14, "sha")
+	g.SetTool("tool-name", "")
 
-	// Path is set to non existing file path for mock test not to use last commit id of the line.
-	// If setting exists file path, sha is changed by last commit id.
-	comments := []*reviewdog.Comment{
-		{
-			Result: &filter.FilteredDiagnostic{
-				Diagnostic: &rdf.Diagnostic{
-					Location: &rdf.Location{
-						Path: "notExistFile.go",
-						Range: &rdf.Range{Start: &rdf.Position{
-							Line: 1,
-						}},
-					},
-					Message: "already commented",
-				},
-				InDiffFile: true,
-			},
-		},
-		{
-			Result: &filter.FilteredDiagnostic{
-				Diagnostic: &rdf.Diagnostic{
-					Location: &rdf.Location{
-						Path: "notExistFile.go",
-						Range: &rdf.Range{Start: &rdf.Position{
-							Line: 14,
-						}},
-					},
-					Message: "new comment",
-				},
-				InDiffFile: true,
-			},
-		},
-	}
+	comments := []*reviewdog.Comment{alreadyCommented, commentedOnPreviousCommit, commentedOnForcePushedCommit, newComment1}
 	for _, c := range comments {
 		if err := g.Post(context.Background(), c); err != nil {
 			t.Error(err)
@@ -127,7 +182,36 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 	if err := g.Flush(context.Background()); err != nil {
 		t.Error(err)
 	}
-	if want := 3; apiCalled != want {
+	if want := 6; apiCalled != want {
 		t.Errorf("GitLab API is called %d times, want %d times", apiCalled, want)
 	}
 }
+
+func TestGitLabMergeRequestCommitCommenter_Flush_listCommentsError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "0123456789abcdef"}]`))
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/repository/commits/0123456789abcdef/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGitLabMergeRequestCommitCommenter(cli, "o", "r", 14, "sha")
+	// Comments must not be posted without checking posted comments, which
+	// would duplicate them.
+	if err := g.Flush(context.Background()); err == nil {
+		t.Error("want error if comments of commits cannot be listed")
+	}
+}
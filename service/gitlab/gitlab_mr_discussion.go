@@ -2,8 +2,10 @@ package gitlab
 
 import (
 	"context"
+	"crypto/sha1"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +19,8 @@ import (
 	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
+var _ reviewdog.FinishCommentService = &MergeRequestDiscussionCommenter{}
+
 const (
 	invalidSuggestionPre  = "<details><summary>reviewdog suggestion error</summary>"
 	invalidSuggestionPost = "</details>"
@@ -28,6 +32,13 @@ const (
 //
 //	https://docs.gitlab.com/ee/api/discussions.html#create-new-merge-request-discussion
 //	POST /projects/:id/merge_requests/:merge_request_iid/discussions
+//
+// In draft notes mode:
+//
+//	https://docs.gitlab.com/api/draft_notes/#create-a-draft-note
+//	POST /projects/:id/merge_requests/:merge_request_iid/draft_notes
+//	https://docs.gitlab.com/api/draft_notes/#publish-all-pending-draft-notes
+//	POST /projects/:id/merge_requests/:merge_request_iid/draft_notes/bulk_publish
 type MergeRequestDiscussionCommenter struct {
 	cli      *gitlab.Client
 	pr       int
@@ -35,6 +46,10 @@ type MergeRequestDiscussionCommenter struct {
 	projects string
 	toolName string
 
+	// draftNotes creates draft notes and publishes them at once by Finish
+	// instead of creating discussions one by one.
+	draftNotes bool
+
 	muComments   sync.Mutex
 	postComments []*reviewdog.Comment
 
@@ -63,6 +78,15 @@ func (g *MergeRequestDiscussionCommenter) SetTool(toolName string, _ string) {
 	g.toolName = toolName
 }
 
+// EnableDraftNotes makes Flush create draft notes for new findings, and Finish
+// publish them at once after all tools run, so that reviewers get a single
+// notification. As GitLab only supports publishing all pending draft notes of
+// the API token user, other pending draft notes of the user are published
+// together.
+func (g *MergeRequestDiscussionCommenter) EnableDraftNotes() {
+	g.draftNotes = true
+}
+
 // Post accepts a comment and holds it. Flush method actually posts comments to
 // GitLab in parallel.
 func (g *MergeRequestDiscussionCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
@@ -108,7 +132,8 @@ func (g *MergeRequestDiscussionCommenter) setPostedComments() error {
 	for _, d := range discussions {
 		for _, note := range d.Notes {
 			pos := note.Position
-			if pos == nil || pos.NewPath == "" || pos.NewLine == 0 || note.Body == "" {
+			// NewLine is 0 for file-level discussions.
+			if pos == nil || pos.NewPath == "" || note.Body == "" {
 				continue
 			}
 			meta := serviceutil.ExtractMetaComment(note.Body)
@@ -122,6 +147,24 @@ func (g *MergeRequestDiscussionCommenter) setPostedComments() error {
 			}
 		}
 	}
+	if !g.draftNotes {
+		return nil
+	}
+	// Draft notes which are not published yet (e.g. a previous run failed
+	// before Finish) are published by Finish, so don't create them again.
+	drafts, err := listAllDraftNotes(g.cli, g.projects, g.pr)
+	if err != nil {
+		return fmt.Errorf("failed to list all merge request draft notes: %w", err)
+	}
+	for _, note := range drafts {
+		pos := note.Position
+		if pos == nil || pos.NewPath == "" {
+			continue
+		}
+		if meta := serviceutil.ExtractMetaComment(note.Note); meta != nil {
+			g.postedcs.AddPostedComment(pos.NewPath, int(pos.NewLine), meta.GetFingerprint())
+		}
+	}
 	return nil
 }
 
@@ -135,54 +178,85 @@ func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Contex
 		return err
 	}
 
+	blobBaseURL := gitlabBlobBaseURL(mr.WebURL, g.sha)
+
 	var eg errgroup.Group
 	for _, c := range g.postComments {
 		c := c
-		loc := c.Result.Diagnostic.GetLocation()
-		lnum := int(loc.GetRange().GetStart().GetLine())
-		if !c.Result.InDiffFile || lnum == 0 {
+		if !c.Result.InDiffFile {
 			continue
 		}
 		fprint, err := serviceutil.Fingerprint(c.Result.Diagnostic)
 		if err != nil {
 			return err
 		}
-		if g.postedcs.IsPosted(c, lnum, fprint) {
+		if g.postedcs.IsPosted(c, gitlabCommentLine(c), fprint) {
 			delete(g.outdatedDiscussions, fprint)
 			continue
 		}
-		body := commentutil.MarkdownComment(c)
-		if suggestion := buildSuggestions(c); suggestion != "" {
-			body = body + "\n\n" + suggestion
-		}
-		body += fmt.Sprintf("\n%s\n", serviceutil.BuildMetaComment(fprint, g.toolName))
+		body := buildBody(c, blobBaseURL, fprint, g.toolName)
+		pos := buildPosition(c, targetBranch.Commit.ID, g.sha)
 		eg.Go(func() error {
-			pos := &gitlab.PositionOptions{
-				StartSHA:     gitlab.Ptr(targetBranch.Commit.ID),
-				HeadSHA:      gitlab.Ptr(g.sha),
-				BaseSHA:      gitlab.Ptr(targetBranch.Commit.ID),
-				PositionType: gitlab.Ptr("text"),
-				NewPath:      gitlab.Ptr(loc.GetPath()),
-				NewLine:      gitlab.Ptr(int64(lnum)),
-			}
-			if c.Result.OldPath != "" && c.Result.OldLine != 0 {
-				pos.OldPath = gitlab.Ptr(c.Result.OldPath)
-				pos.OldLine = gitlab.Ptr(int64(c.Result.OldLine))
-			}
-			discussion := &gitlab.CreateMergeRequestDiscussionOptions{
-				Body:     gitlab.Ptr(body),
-				Position: pos,
-			}
-			_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, int64(g.pr), discussion)
-			if err != nil {
-				return fmt.Errorf("failed to create merge request discussion: %w", err)
-			}
-			return nil
+			return g.createDiscussion(ctx, body, pos)
 		})
 	}
 	return eg.Wait()
 }
 
+// Finish publishes all pending draft notes at once in draft notes mode if
+// there are draft notes created by reviewdog. Other pending draft notes of the
+// API token user are published together, which is logged as a warning.
+func (g *MergeRequestDiscussionCommenter) Finish(ctx context.Context) error {
+	if !g.draftNotes {
+		return nil
+	}
+	drafts, err := listAllDraftNotes(g.cli, g.projects, g.pr)
+	if err != nil {
+		return fmt.Errorf("failed to list all merge request draft notes: %w", err)
+	}
+	var ours, others int
+	for _, note := range drafts {
+		if serviceutil.ExtractMetaComment(note.Note) != nil {
+			ours++
+		} else {
+			others++
+		}
+	}
+	if ours == 0 {
+		return nil
+	}
+	if others > 0 {
+		log.Printf("reviewdog: %d pending draft notes not created by reviewdog are published together", others)
+	}
+	if _, err := g.cli.DraftNotes.PublishAllDraftNotes(g.projects, int64(g.pr), gitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to publish draft notes: %w", err)
+	}
+	return nil
+}
+
+// createDiscussion creates a discussion, or a draft note in draft notes mode.
+func (g *MergeRequestDiscussionCommenter) createDiscussion(ctx context.Context, body string, pos *gitlab.PositionOptions) error {
+	if g.draftNotes {
+		note := &gitlab.CreateDraftNoteOptions{
+			Note:     gitlab.Ptr(body),
+			Position: pos,
+		}
+		if _, _, err := g.cli.DraftNotes.CreateDraftNote(g.projects, int64(g.pr), note, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("failed to create merge request draft note: %w", err)
+		}
+		return nil
+	}
+	discussion := &gitlab.CreateMergeRequestDiscussionOptions{
+		Body:     gitlab.Ptr(body),
+		Position: pos,
+	}
+	_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, int64(g.pr), discussion)
+	if err != nil {
+		return fmt.Errorf("failed to create merge request discussion: %w", err)
+	}
+	return nil
+}
+
 // resolveOutdatedDiscussions marks previously-posted reviewdog discussions as
 // resolved when the corresponding diagnostic is no longer reported in the
 // current run. This keeps a MR review clean as fixes land.
@@ -221,6 +295,157 @@ func (g *MergeRequestDiscussionCommenter) resolveOutdatedDiscussions(ctx context
 	return errors.Join(errs...)
 }
 
+func buildBody(c *reviewdog.Comment, blobBaseURL string, fprint string, toolName string) string {
+	body := commentutil.MarkdownComment(c)
+	if c.Result.InDiffContext {
+		if suggestion := buildSuggestions(c); suggestion != "" {
+			body = body + "\n\n" + suggestion
+		}
+	} else if u := gitlabCodeSnippetURL(blobBaseURL, c.Result.Diagnostic.GetLocation()); u != "" {
+		// File-level discussions don't show the line, so link to it.
+		body += "\n\n" + u
+	}
+	if related := buildRelatedLocations(c, blobBaseURL); related != "" {
+		body += "\n\n" + related
+	}
+	body += fmt.Sprintf("\n%s\n", serviceutil.BuildMetaComment(fprint, toolName))
+	return body
+}
+
+// buildRelatedLocations renders related locations as a list of permalinks.
+func buildRelatedLocations(c *reviewdog.Comment, blobBaseURL string) string {
+	var sb strings.Builder
+	for _, rel := range c.Result.Diagnostic.GetRelatedLocations() {
+		loc := rel.GetLocation()
+		if loc.GetPath() == "" {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("**Related locations:**\n")
+		}
+		label := loc.GetPath()
+		if start := loc.GetRange().GetStart().GetLine(); start > 0 {
+			label += fmt.Sprintf(":%d", start)
+		}
+		u := gitlabCodeSnippetURL(blobBaseURL, loc)
+		if u == "" && blobBaseURL != "" {
+			u = blobBaseURL + "/" + loc.GetPath()
+		}
+		if u != "" {
+			sb.WriteString(fmt.Sprintf("- [%s](%s)", label, u))
+		} else {
+			sb.WriteString("- " + label)
+		}
+		if msg := rel.GetMessage(); msg != "" {
+			sb.WriteString(": " + msg)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// buildPosition builds a position of a discussion. It's a file-level
+// position if the result is outside diff context (e.g. -filter-mode=file) or
+// doesn't have a line, otherwise it's a single line or multi-line text
+// position.
+//
+// https://docs.gitlab.com/api/discussions/#create-a-new-thread-in-the-merge-request-diff
+func buildPosition(c *reviewdog.Comment, baseSHA, headSHA string) *gitlab.PositionOptions {
+	loc := c.Result.Diagnostic.GetLocation()
+	pos := &gitlab.PositionOptions{
+		StartSHA:     gitlab.Ptr(baseSHA),
+		HeadSHA:      gitlab.Ptr(headSHA),
+		BaseSHA:      gitlab.Ptr(baseSHA),
+		PositionType: gitlab.Ptr("text"),
+		NewPath:      gitlab.Ptr(loc.GetPath()),
+	}
+	start, end := gitlabCommentLineRange(c)
+	if !c.Result.InDiffContext || start == 0 {
+		pos.PositionType = gitlab.Ptr("file")
+		pos.OldPath = gitlab.Ptr(loc.GetPath())
+		if c.Result.OldPath != "" {
+			pos.OldPath = gitlab.Ptr(c.Result.OldPath)
+		}
+		return pos
+	}
+	// GitLab shows a multi-line discussion at its end line.
+	pos.NewLine = gitlab.Ptr(int64(end))
+	oldEnd := c.Result.OldLine
+	if start != end {
+		oldEnd = c.Result.OldEndLine
+		pos.LineRange = &gitlab.LineRangeOptions{
+			Start: buildLinePosition(loc.GetPath(), c.Result.OldLine, start),
+			End:   buildLinePosition(loc.GetPath(), oldEnd, end),
+		}
+	}
+	if c.Result.OldPath != "" && oldEnd != 0 {
+		pos.OldPath = gitlab.Ptr(c.Result.OldPath)
+		pos.OldLine = gitlab.Ptr(int64(oldEnd))
+	}
+	return pos
+}
+
+// buildLinePosition builds a start or end position of a line range. The line
+// code is "<SHA1 of path>_<old line>_<new line>".
+func buildLinePosition(path string, oldLine, newLine int) *gitlab.LinePositionOptions {
+	p := &gitlab.LinePositionOptions{
+		LineCode: gitlab.Ptr(fmt.Sprintf("%x_%d_%d", sha1.Sum([]byte(path)), oldLine, newLine)),
+		Type:     gitlab.Ptr("new"),
+		NewLine:  gitlab.Ptr(int64(newLine)),
+	}
+	if oldLine != 0 {
+		// Unchanged line.
+		p.Type = gitlab.Ptr("old")
+		p.OldLine = gitlab.Ptr(int64(oldLine))
+	}
+	return p
+}
+
+// gitlabCommentLine returns the new line of the discussion position. It's the
+// end line for multi-line discussions and 0 for file-level discussions.
+func gitlabCommentLine(c *reviewdog.Comment) int {
+	if !c.Result.InDiffContext {
+		return 0
+	}
+	_, end := gitlabCommentLineRange(c)
+	return end
+}
+
+func gitlabCommentLineRange(c *reviewdog.Comment) (start int, end int) {
+	rng := c.Result.Diagnostic.GetLocation().GetRange()
+	start = int(rng.GetStart().GetLine())
+	end = int(rng.GetEnd().GetLine())
+	if end == 0 {
+		end = start
+	}
+	return start, end
+}
+
+// gitlabBlobBaseURL returns the base URL of files at the given sha from the
+// web URL of a merge request (e.g. https://gitlab.com/o/r/-/merge_requests/1).
+func gitlabBlobBaseURL(mrWebURL, sha string) string {
+	projectURL, _, ok := strings.Cut(mrWebURL, "/-/merge_requests/")
+	if !ok {
+		return ""
+	}
+	return projectURL + "/-/blob/" + sha
+}
+
+func gitlabCodeSnippetURL(blobBaseURL string, loc *rdf.Location) string {
+	if blobBaseURL == "" {
+		return ""
+	}
+	start, end := loc.GetRange().GetStart().GetLine(), loc.GetRange().GetEnd().GetLine()
+	if start == 0 {
+		return ""
+	}
+	u := blobBaseURL + "/" + loc.GetPath() + fmt.Sprintf("#L%d", start)
+	if end > start {
+		u += fmt.Sprintf("-%d", end)
+	}
+	return u
+}
+
 func listAllMergeRequestDiscussion(cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.ListMergeRequestDiscussionsOptions) ([]*gitlab.Discussion, error) {
 	discussions, resp, err := cli.Discussions.ListMergeRequestDiscussions(projectID, int64(mergeRequest), opts)
 	if err != nil {
@@ -242,6 +467,22 @@ func listAllMergeRequestDiscussion(cli *gitlab.Client, projectID string, mergeRe
 	return append(discussions, restDiscussions...), nil
 }
 
+func listAllDraftNotes(cli *gitlab.Client, projectID string, mergeRequest int) ([]*gitlab.DraftNote, error) {
+	var all []*gitlab.DraftNote
+	opts := &gitlab.ListDraftNotesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		notes, resp, err := cli.DraftNotes.ListDraftNotes(projectID, int64(mergeRequest), opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, notes...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
 // creates diff in markdown for suggested changes
 // Ref gitlab suggestion: https://docs.gitlab.com/ee/user/project/merge_requests/reviews/suggestions.html
 func buildSuggestions(c *reviewdog.Comment) string {
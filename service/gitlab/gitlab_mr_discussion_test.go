@@ -2,7 +2,9 @@ package gitlab
 
 import (
 	"context"
+	"crypto/sha1"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -61,7 +63,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "already commented",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	alreadyCommented2 := &reviewdog.Comment{
@@ -75,7 +78,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "already commented 2",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment1 := &reviewdog.Comment{
@@ -89,7 +93,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment2 := &reviewdog.Comment{
@@ -103,7 +108,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment 2",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment3 := &reviewdog.Comment{
@@ -117,9 +123,10 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment 3",
 			},
-			OldPath:    "old_file.go",
-			OldLine:    7,
-			InDiffFile: true,
+			OldPath:       "old_file.go",
+			OldLine:       7,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	commentOutsideDiff := &reviewdog.Comment{
@@ -172,7 +179,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 					},
 				},
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 
@@ -187,7 +195,7 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 		newCommentWithSuggestion,
 	}
 	var postCalled int32
-	const wantPostCalled = 4
+	const wantPostCalled = 5
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
@@ -311,6 +319,21 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				if diff := cmp.Diff(got, want); diff != "" {
 					t.Error(diff)
 				}
+			case "path.go":
+				want := &gitlab.CreateMergeRequestDiscussionOptions{
+					Body: gitlab.Ptr(metaBody(t, commentWithoutLnum, "tool-name")),
+					Position: &gitlab.PositionOptions{
+						BaseSHA:      gitlab.Ptr("xxx"),
+						StartSHA:     gitlab.Ptr("xxx"),
+						HeadSHA:      gitlab.Ptr("sha"),
+						PositionType: gitlab.Ptr("file"),
+						NewPath:      gitlab.Ptr("path.go"),
+						OldPath:      gitlab.Ptr("path.go"),
+					},
+				}
+				if diff := cmp.Diff(got, want); diff != "" {
+					t.Error(diff)
+				}
 			default:
 				t.Errorf("got unexpected discussion: %#v", got)
 			}
@@ -373,7 +396,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_resolvesOutdatedDiscussions
 				},
 				Message: "still reported",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	// Diagnostic that was previously posted but is NOT reported this run —
@@ -387,7 +411,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_resolvesOutdatedDiscussions
 				},
 				Message: "fixed in new run",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	// A previously-posted comment from a DIFFERENT tool must not be resolved
@@ -401,7 +426,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_resolvesOutdatedDiscussions
 				},
 				Message: "from another tool",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 
@@ -549,7 +575,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_skipsUnresolvable(t *testin
 				},
 				Message: "unresolvable thread",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 
@@ -621,7 +648,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_propagatesResolveError(t *t
 				},
 				Message: "fixed in new run",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 
@@ -692,7 +720,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Flush_paginatedOutdated(t *testin
 				},
 				Message: "fixed on page 2",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 
@@ -908,3 +937,343 @@ func buildTestComment(message string, suggestions []*rdf.Suggestion) *reviewdog.
 		},
 	}
 }
+
+func TestGitLabMergeRequestDiscussionCommenter_Flush_draftNotes(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile:    true,
+				InDiffContext: true,
+			},
+		}
+	}
+	comments := []*reviewdog.Comment{newComment(1, "first"), newComment(2, "second")}
+
+	// A draft note of "first" is left by a previous run which failed before
+	// publishing, and another draft note is written by the token user.
+	var (
+		mu     sync.Mutex
+		drafts = []*gitlab.DraftNote{
+			{ID: 1, Note: metaBodyForNote(t, comments[0], "tool-name"), Position: &gitlab.NotePosition{NewPath: "file.go", NewLine: 1}},
+			{ID: 2, Note: "LGTM"},
+		}
+		draftCalled int
+		published   int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /api/v4/projects/o%2Fr/merge_requests/14/draft_notes", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := json.NewEncoder(w).Encode(drafts); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.HandleFunc("POST /api/v4/projects/o%2Fr/merge_requests/14/draft_notes", func(w http.ResponseWriter, r *http.Request) {
+		var got gitlab.CreateDraftNoteOptions
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.Position == nil || got.Position.NewPath == nil || *got.Position.NewPath != "file.go" {
+			t.Errorf("unexpected draft note position: %#v", got.Position)
+		}
+		if !strings.Contains(*got.Note, "second") {
+			t.Errorf("unexpected draft note: %s", *got.Note)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		draftCalled++
+		draft := &gitlab.DraftNote{ID: int64(100 + draftCalled), Note: *got.Note, Position: &gitlab.NotePosition{NewPath: "file.go", NewLine: 2}}
+		drafts = append(drafts, draft)
+		if err := json.NewEncoder(w).Encode(draft); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.HandleFunc("PUT /api/v4/projects/o%2Fr/merge_requests/14/draft_notes/{id}/publish", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+	})
+	mux.HandleFunc("POST /api/v4/projects/o%2Fr/merge_requests/14/draft_notes/bulk_publish", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		published++
+		drafts = nil
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha")
+	g.SetTool("tool-name", "")
+	g.EnableDraftNotes()
+	for _, c := range comments {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// The draft note of "first" isn't created again.
+	if draftCalled != 1 {
+		t.Errorf("%d draft notes created, but want 1", draftCalled)
+	}
+	// Draft notes are published once after all tools by Finish.
+	g.SetTool("another-tool", "")
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if published != 0 {
+		t.Errorf("draft notes published %d times before Finish", published)
+	}
+	if err := g.Finish(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if published != 1 {
+		t.Errorf("draft notes published %d times, want 1", published)
+	}
+
+	// Nothing to publish anymore.
+	if err := g.Finish(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if published != 1 {
+		t.Errorf("draft notes published %d times, want 1", published)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Flush_multiLineAndFileLevel(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	multiLine := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 10},
+						End:   &rdf.Position{Line: 12},
+					},
+				},
+				Message: "multi-line",
+			},
+			OldPath:       "file.go",
+			OldLine:       8,
+			OldEndLine:    0, // added line
+			InDiffFile:    true,
+			InDiffContext: true,
+		},
+	}
+	outsideDiffContext := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file2.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 3},
+						End:   &rdf.Position{Line: 5},
+					},
+				},
+				Message: "outside diff context",
+			},
+			OldPath:    "old_file2.go",
+			InDiffFile: true,
+		},
+	}
+	alreadyFileLevel := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "file3.go"},
+				Message:  "already posted file-level",
+			},
+			InDiffFile: true,
+		},
+	}
+
+	pathCode := func(path string) string {
+		return fmt.Sprintf("%x", sha1.Sum([]byte(path)))
+	}
+	var postCalled int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			dls := []*gitlab.Discussion{{
+				ID: "file-level",
+				Notes: []*gitlab.Note{{
+					Body:       metaBodyForNote(t, alreadyFileLevel, "tool-name"),
+					Resolvable: true,
+					Position: &gitlab.NotePosition{
+						PositionType: "file",
+						NewPath:      "file3.go",
+					},
+				}},
+			}}
+			if err := json.NewEncoder(w).Encode(dls); err != nil {
+				t.Fatal(err)
+			}
+		case http.MethodPost:
+			atomic.AddInt32(&postCalled, 1)
+			got := new(gitlab.CreateMergeRequestDiscussionOptions)
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Error(err)
+			}
+			var want *gitlab.CreateMergeRequestDiscussionOptions
+			switch *got.Position.NewPath {
+			case "file.go":
+				want = &gitlab.CreateMergeRequestDiscussionOptions{
+					Body: gitlab.Ptr(metaBody(t, multiLine, "tool-name")),
+					Position: &gitlab.PositionOptions{
+						BaseSHA:      gitlab.Ptr("xxx"),
+						StartSHA:     gitlab.Ptr("xxx"),
+						HeadSHA:      gitlab.Ptr("sha"),
+						PositionType: gitlab.Ptr("text"),
+						NewPath:      gitlab.Ptr("file.go"),
+						NewLine:      gitlab.Ptr(int64(12)),
+						LineRange: &gitlab.LineRangeOptions{
+							Start: &gitlab.LinePositionOptions{
+								LineCode: gitlab.Ptr(pathCode("file.go") + "_8_10"),
+								Type:     gitlab.Ptr("old"),
+								OldLine:  gitlab.Ptr(int64(8)),
+								NewLine:  gitlab.Ptr(int64(10)),
+							},
+							End: &gitlab.LinePositionOptions{
+								LineCode: gitlab.Ptr(pathCode("file.go") + "_0_12"),
+								Type:     gitlab.Ptr("new"),
+								NewLine:  gitlab.Ptr(int64(12)),
+							},
+						},
+					},
+				}
+			case "file2.go":
+				fprint, err := serviceutil.Fingerprint(outsideDiffContext.Result.Diagnostic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want = &gitlab.CreateMergeRequestDiscussionOptions{
+					Body: gitlab.Ptr(commentutil.MarkdownComment(outsideDiffContext) +
+						"\n\nhttps://gitlab.example.com/o/r/-/blob/sha/file2.go#L3-5\n" +
+						serviceutil.BuildMetaComment(fprint, "tool-name") + "\n"),
+					Position: &gitlab.PositionOptions{
+						BaseSHA:      gitlab.Ptr("xxx"),
+						StartSHA:     gitlab.Ptr("xxx"),
+						HeadSHA:      gitlab.Ptr("sha"),
+						PositionType: gitlab.Ptr("file"),
+						NewPath:      gitlab.Ptr("file2.go"),
+						OldPath:      gitlab.Ptr("old_file2.go"),
+					},
+				}
+			default:
+				t.Errorf("got unexpected discussion: %#v", got)
+				return
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Error(diff)
+			}
+			if err := json.NewEncoder(w).Encode(gitlab.Discussion{}); err != nil {
+				t.Fatal(err)
+			}
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14/discussions/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected resolve call: %v %v", r.Method, r.URL)
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch", "web_url": "https://gitlab.example.com/o/r/-/merge_requests/14"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha")
+	g.SetTool("tool-name", "")
+	for _, c := range []*reviewdog.Comment{multiLine, outsideDiffContext, alreadyFileLevel} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if postCalled != 2 {
+		t.Errorf("%d discussions posted, but want 2", postCalled)
+	}
+}
+
+func TestBuildRelatedLocations(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Message: "tainted data reaches sink",
+				RelatedLocations: []*rdf.RelatedLocation{
+					{
+						Message: "source",
+						Location: &rdf.Location{
+							Path: "source.go",
+							Range: &rdf.Range{
+								Start: &rdf.Position{Line: 3},
+								End:   &rdf.Position{Line: 5},
+							},
+						},
+					},
+					{
+						Location: &rdf.Location{Path: "config.go"},
+					},
+					{
+						Message: "no path",
+					},
+				},
+			},
+		},
+	}
+	const blobBaseURL = "https://gitlab.example.com/o/r/-/blob/sha"
+	want := "**Related locations:**\n" +
+		"- [source.go:3](https://gitlab.example.com/o/r/-/blob/sha/source.go#L3-5): source\n" +
+		"- [config.go](https://gitlab.example.com/o/r/-/blob/sha/config.go)\n"
+	if got := buildRelatedLocations(c, blobBaseURL); got != want {
+		t.Errorf("buildRelatedLocations() =\n%s\nwant:\n%s", got, want)
+	}
+	wantNoLink := "**Related locations:**\n- source.go:3: source\n- config.go\n"
+	if got := buildRelatedLocations(c, ""); got != wantNoLink {
+		t.Errorf("buildRelatedLocations() without blob URL =\n%s\nwant:\n%s", got, wantNoLink)
+	}
+}
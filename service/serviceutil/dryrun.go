@@ -0,0 +1,101 @@
+package serviceutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DryRunRequest is a request which is not sent in dry-run mode.
+type DryRunRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Body is the request payload. Non-JSON payload is stored as a JSON string.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// DryRunTransport is a http.RoundTripper which sends only safe requests (GET,
+// HEAD and OPTIONS) and writes other requests to W as JSON Lines of
+// DryRunRequest instead of sending them. Each of them gets a successful
+// response with Response body, so API clients work as usual.
+type DryRunTransport struct {
+	// Base is used to send safe requests. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+	W    io.Writer
+	// Response is the body of fake responses. An empty JSON object is used if
+	// it's empty. Services whose responses have a special format (e.g. Gerrit
+	// prepends an XSSI-defeating prefix to JSON) should set it.
+	Response string
+
+	mu sync.Mutex
+}
+
+// NewDryRunTransport returns a new DryRunTransport.
+func NewDryRunTransport(base http.RoundTripper, w io.Writer) *DryRunTransport {
+	return &DryRunTransport{Base: base, W: w}
+}
+
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.base().RoundTrip(req)
+	}
+	if err := t.write(req); err != nil {
+		return nil, err
+	}
+	res := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(t.response())),
+		Request:    req,
+	}
+	if req.Method == http.MethodDelete {
+		res.Status = "204 No Content"
+		res.StatusCode = http.StatusNoContent
+		res.Header = http.Header{}
+		res.Body = http.NoBody
+	}
+	return res, nil
+}
+
+func (t *DryRunTransport) write(req *http.Request) error {
+	r := &DryRunRequest{Method: req.Method, URL: req.URL.String()}
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body in dry-run mode: %w", err)
+		}
+		switch {
+		case len(bytes.TrimSpace(b)) == 0:
+		case json.Valid(b):
+			r.Body = bytes.TrimSpace(b)
+		default:
+			r.Body, _ = json.Marshal(string(b))
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.NewEncoder(t.W).Encode(r)
+}
+
+func (t *DryRunTransport) response() string {
+	if t.Response != "" {
+		return t.Response
+	}
+	return "{}"
+}
+
+func (t *DryRunTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,70 @@
+package serviceutil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDryRunTransport(t *testing.T) {
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		io.WriteString(w, `{"read":true}`)
+	}))
+	defer ts.Close()
+
+	var out bytes.Buffer
+	cli := &http.Client{Transport: NewDryRunTransport(nil, &out)}
+
+	res, err := cli.Get(ts.URL + "/comments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(res.Body); string(b) != `{"read":true}` {
+		t.Errorf("GET response body = %q, want the server response", b)
+	}
+	res.Body.Close()
+
+	res, err = cli.Post(ts.URL+"/review", "application/json", strings.NewReader(`{"body": "lgtm"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(res.Body); res.StatusCode != http.StatusOK || string(b) != "{}" {
+		t.Errorf("POST response = %d %q, want 200 {}", res.StatusCode, b)
+	}
+	res.Body.Close()
+
+	res, err = cli.Post(ts.URL+"/raw", "text/plain", strings.NewReader("not json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/comments/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = cli.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	res.Body.Close()
+
+	if len(sent) != 1 || sent[0] != "GET /comments" {
+		t.Errorf("sent requests = %v, want only [GET /comments]", sent)
+	}
+	want := `{"method":"POST","url":"` + ts.URL + `/review","body":{"body":"lgtm"}}
+{"method":"POST","url":"` + ts.URL + `/raw","body":"not json"}
+{"method":"DELETE","url":"` + ts.URL + `/comments/1"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("dry-run output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
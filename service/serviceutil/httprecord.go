@@ -0,0 +1,250 @@
+package serviceutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HTTPInteraction is a pair of recorded HTTP request and response. It's saved
+// as a JSON file (fixture) by HTTPRecorder and loaded by HTTPReplayer.
+//
+// Request headers are not recorded so that fixtures don't contain credentials.
+type HTTPInteraction struct {
+	Request  HTTPRecordedRequest  `json:"request"`
+	Response HTTPRecordedResponse `json:"response"`
+}
+
+// HTTPRecordedRequest is a recorded HTTP request.
+type HTTPRecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	HTTPRecordedBody
+}
+
+// HTTPRecordedResponse is a recorded HTTP response.
+type HTTPRecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	HTTPRecordedBody
+}
+
+// HTTPRecordedBody is a recorded HTTP body. JSON body is stored as it is to
+// keep fixtures readable and other body is stored as a string.
+type HTTPRecordedBody struct {
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"body_text,omitempty"`
+}
+
+func newHTTPRecordedBody(b []byte) HTTPRecordedBody {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return HTTPRecordedBody{}
+	}
+	if json.Valid(b) {
+		return HTTPRecordedBody{Body: b}
+	}
+	return HTTPRecordedBody{BodyText: string(b)}
+}
+
+func (b HTTPRecordedBody) bytes() []byte {
+	if len(b.Body) > 0 {
+		// Fixtures are indented.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b.Body); err == nil {
+			return buf.Bytes()
+		}
+		return b.Body
+	}
+	return []byte(b.BodyText)
+}
+
+// HTTPRecorder records HTTP interactions of transports created by Transport
+// method to fixture files in a directory. One recorder should be shared by
+// all API clients so that fixtures are numbered in request order.
+type HTTPRecorder struct {
+	dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewHTTPRecorder returns a new HTTPRecorder which saves fixtures to dir. dir
+// is created if it doesn't exist.
+func NewHTTPRecorder(dir string) (*HTTPRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create HTTP record directory: %w", err)
+	}
+	return &HTTPRecorder{dir: dir}, nil
+}
+
+// Transport returns a http.RoundTripper which sends requests with base and
+// records them. http.DefaultTransport is used if base is nil.
+func (r *HTTPRecorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordTransport{recorder: r, base: base}
+}
+
+type recordTransport struct {
+	recorder *HTTPRecorder
+	base     http.RoundTripper
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	header := res.Header.Clone()
+	header.Del("Set-Cookie")
+	in := &HTTPInteraction{
+		Request: HTTPRecordedRequest{
+			Method:           req.Method,
+			URL:              req.URL.String(),
+			HTTPRecordedBody: newHTTPRecordedBody(reqBody),
+		},
+		Response: HTTPRecordedResponse{
+			StatusCode:       res.StatusCode,
+			Header:           header,
+			HTTPRecordedBody: newHTTPRecordedBody(resBody),
+		},
+	}
+	if err := t.recorder.save(in); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var fixtureNameReplacer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (r *HTTPRecorder) save(in *HTTPInteraction) error {
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	path := fixtureNameReplacer.ReplaceAllString(strings.Trim(requestPath(in.Request.URL), "/"), "_")
+	if len(path) > 80 {
+		path = path[:80]
+	}
+	name := fmt.Sprintf("%04d-%s-%s.json", r.seq, in.Request.Method, path)
+	if err := os.WriteFile(filepath.Join(r.dir, name), append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save HTTP fixture: %w", err)
+	}
+	return nil
+}
+
+// HTTPReplayer is a http.RoundTripper which replays HTTP interactions
+// recorded by HTTPRecorder without network access.
+//
+// Requests are matched by method, path and query. Host is ignored so that
+// fixtures can be replayed against any base URL. Interactions with the same
+// request are replayed in recorded order and the last one is reused after
+// all of them are replayed.
+type HTTPReplayer struct {
+	mu           sync.Mutex
+	interactions map[string][]*HTTPInteraction
+}
+
+// NewHTTPReplayer loads fixtures in dir and returns a new HTTPReplayer.
+func NewHTTPReplayer(dir string) (*HTTPReplayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no HTTP fixtures found in %s", dir)
+	}
+	sort.Strings(files)
+	r := &HTTPReplayer{interactions: make(map[string][]*HTTPInteraction)}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		in := new(HTTPInteraction)
+		if err := json.Unmarshal(b, in); err != nil {
+			return nil, fmt.Errorf("failed to load HTTP fixture %s: %w", f, err)
+		}
+		key := replayKey(in.Request.Method, in.Request.URL)
+		r.interactions[key] = append(r.interactions[key], in)
+	}
+	return r, nil
+}
+
+func (r *HTTPReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := replayKey(req.Method, req.URL.String())
+	r.mu.Lock()
+	ins := r.interactions[key]
+	if len(ins) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded HTTP response for %s", key)
+	}
+	in := ins[0]
+	if len(ins) > 1 {
+		r.interactions[key] = ins[1:]
+	}
+	r.mu.Unlock()
+
+	res := in.Response
+	header := res.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode: res.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(res.bytes())),
+		Request:    req,
+	}, nil
+}
+
+func replayKey(method, rawURL string) string {
+	return method + " " + requestPath(rawURL)
+}
+
+// requestPath returns path and query of the given URL.
+func requestPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.RequestURI()
+}
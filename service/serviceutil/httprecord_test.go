@@ -0,0 +1,113 @@
+package serviceutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRecorder_replay(t *testing.T) {
+	comments := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /comments", func(w http.ResponseWriter, r *http.Request) {
+		comments++
+		w.Header().Set("Link", `<https://example.com/comments?page=2>; rel="next"`)
+		w.Header().Set("Set-Cookie", "secret=1")
+		io.WriteString(w, `[{"id":`+r.URL.Query().Get("page")+`}]`)
+	})
+	mux.HandleFunc("GET /diff", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "--- a/reviewdog.go\n+++ b/reviewdog.go\n")
+	})
+	mux.HandleFunc("POST /review", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(b)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	recorder, err := NewHTTPRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/comments?page=1", ""},
+		{http.MethodGet, "/diff", ""},
+		{http.MethodPost, "/review", `{"body":"lgtm"}`},
+		{http.MethodPost, "/review", `{"body":"nit"}`},
+	}
+	do := func(cli *http.Client, baseURL, method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, baseURL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := cli.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, string(b)
+	}
+
+	recordCli := &http.Client{Transport: recorder.Transport(nil)}
+	var want []string
+	for _, r := range requests {
+		_, body := do(recordCli, ts.URL, r.method, r.path, r.body)
+		want = append(want, body)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != len(requests) {
+		t.Fatalf("recorded %d fixtures, want %d", len(files), len(requests))
+	}
+	if got := filepath.Base(files[0]); got != "0001-GET-comments_page_1.json" {
+		t.Errorf("fixture name = %q", got)
+	}
+
+	replayer, err := NewHTTPReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayCli := &http.Client{Transport: replayer}
+	// Replay against another host without network access.
+	const baseURL = "https://api.example.com"
+	for i, r := range requests {
+		res, body := do(replayCli, baseURL, r.method, r.path, r.body)
+		if body != want[i] {
+			t.Errorf("replayed %s %s body = %q, want %q", r.method, r.path, body, want[i])
+		}
+		if r.method == http.MethodPost && res.StatusCode != http.StatusCreated {
+			t.Errorf("replayed %s %s status = %d, want %d", r.method, r.path, res.StatusCode, http.StatusCreated)
+		}
+		if r.path == "/comments?page=1" {
+			if res.Header.Get("Link") == "" {
+				t.Error("Link header is not replayed")
+			}
+			if res.Header.Get("Set-Cookie") != "" {
+				t.Error("Set-Cookie header should not be recorded")
+			}
+		}
+	}
+	// The last interaction is reused.
+	if _, body := do(replayCli, baseURL, http.MethodPost, "/review", ""); body != want[3] {
+		t.Errorf("reused body = %q, want %q", body, want[3])
+	}
+	if comments != 1 {
+		t.Errorf("server received %d comments requests, want 1", comments)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/comments?page=2", nil)
+	if _, err := replayCli.Do(req); err == nil {
+		t.Error("want error for a request which is not recorded")
+	}
+}
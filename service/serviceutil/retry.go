@@ -0,0 +1,195 @@
+package serviceutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMaxWait    = time.Minute
+	initialBackoff    = time.Second
+)
+
+type retryableKey struct{}
+
+// WithRetryable returns a context which marks API requests with it safe to
+// retry on server errors even if the method is not idempotent (e.g. POST
+// which sets a commit status).
+func WithRetryable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryableKey{}, true)
+}
+
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	v, _ := req.Context().Value(retryableKey{}).(bool)
+	return v
+}
+
+// RetryTransport is a http.RoundTripper which retries API requests.
+//
+// Throttled requests (429 Too Many Requests, or 403 Forbidden with rate limit
+// headers such as GitHub secondary rate limits) are retried regardless of the
+// method as servers don't process them. Requests which fail with a network
+// error or 5xx are retried only if they are idempotent or marked with
+// WithRetryable.
+//
+// It waits as Retry-After or X-RateLimit-Reset headers say if any, otherwise
+// it waits with exponential backoff.
+type RetryTransport struct {
+	// Base is used to send requests. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+	// MaxRetries is the maximum number of retries per request.
+	MaxRetries int
+	// MaxWait is the maximum duration to wait before a retry. The response is
+	// returned as it is if servers ask to wait longer than it.
+	MaxWait time.Duration
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// NewRetryTransport returns a new RetryTransport with default settings.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: defaultMaxRetries,
+		MaxWait:    defaultMaxWait,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		r := req
+		if body != nil {
+			r = req.Clone(ctx)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		res, err := t.base().RoundTrip(r)
+		wait, reason, retry := t.shouldRetry(req, res, err, attempt)
+		if !retry || attempt >= t.MaxRetries {
+			return res, err
+		}
+		if t.MaxWait > 0 && wait > t.MaxWait {
+			slog.WarnContext(ctx, "reviewdog: API request is throttled longer than the maximum wait, giving up",
+				"method", req.Method, "url", req.URL.String(), "reason", reason, "wait", wait)
+			return res, err
+		}
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		slog.WarnContext(ctx, "reviewdog: retrying API request",
+			"method", req.Method, "url", req.URL.String(), "reason", reason, "wait", wait, "attempt", attempt+1)
+		if err := t.doSleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) shouldRetry(req *http.Request, res *http.Response, err error, attempt int) (wait time.Duration, reason string, retry bool) {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return 0, "", false
+		}
+		return backoff(attempt), err.Error(), isRetryable(req)
+	}
+	if isThrottled(res) {
+		if wait, ok := t.retryAfter(res.Header); ok {
+			return wait, res.Status, true
+		}
+		return backoff(attempt), res.Status, true
+	}
+	switch res.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if wait, ok := t.retryAfter(res.Header); ok {
+			return wait, res.Status, isRetryable(req)
+		}
+		return backoff(attempt), res.Status, isRetryable(req)
+	}
+	return 0, "", false
+}
+
+func isThrottled(res *http.Response) bool {
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return res.Header.Get("Retry-After") != "" ||
+			res.Header.Get("X-RateLimit-Remaining") == "0" ||
+			res.Header.Get("RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+// retryAfter returns duration to wait based on Retry-After header (seconds or
+// HTTP date), or X-RateLimit-Reset (GitHub, Gitea) / RateLimit-Reset (GitLab)
+// header (UNIX time).
+func (t *RetryTransport) retryAfter(h http.Header) (time.Duration, bool) {
+	if v := h.Get("Retry-After"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil {
+			return max(time.Duration(sec)*time.Second, 0), true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(at.Sub(t.timeNow()), 0), true
+		}
+	}
+	for _, name := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if v := h.Get(name); v != "" {
+			if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return max(time.Unix(unix, 0).Sub(t.timeNow()), 0), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func backoff(attempt int) time.Duration {
+	return initialBackoff << attempt
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *RetryTransport) timeNow() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+func (t *RetryTransport) doSleep(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
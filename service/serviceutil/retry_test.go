@@ -0,0 +1,147 @@
+package serviceutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	type response struct {
+		status int
+		header map[string]string
+	}
+	tests := []struct {
+		name       string
+		method     string
+		retryable  bool
+		responses  []response
+		wantStatus int
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "GET server error",
+			method:     http.MethodGet,
+			responses:  []response{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}, {status: http.StatusOK}},
+			wantStatus: http.StatusOK,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "GET server error exceeds max retries",
+			method:     http.MethodGet,
+			responses:  []response{{status: 500}, {status: 500}, {status: 500}, {status: 500}, {status: 200}},
+			wantStatus: http.StatusInternalServerError,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:       "POST server error is not retried",
+			method:     http.MethodPost,
+			responses:  []response{{status: http.StatusBadGateway}, {status: http.StatusCreated}},
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "retryable POST server error",
+			method:     http.MethodPost,
+			retryable:  true,
+			responses:  []response{{status: http.StatusBadGateway}, {status: http.StatusCreated}},
+			wantStatus: http.StatusCreated,
+			wantWaits:  []time.Duration{time.Second},
+		},
+		{
+			name:   "POST too many requests with Retry-After",
+			method: http.MethodPost,
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "5"}},
+				{status: http.StatusCreated},
+			},
+			wantStatus: http.StatusCreated,
+			wantWaits:  []time.Duration{5 * time.Second},
+		},
+		{
+			name:   "POST secondary rate limit with X-RateLimit-Reset",
+			method: http.MethodPost,
+			responses: []response{
+				{status: http.StatusForbidden, header: map[string]string{
+					"X-RateLimit-Remaining": "0",
+					"X-RateLimit-Reset":     strconv.FormatInt(now.Add(10*time.Second).Unix(), 10),
+				}},
+				{status: http.StatusCreated},
+			},
+			wantStatus: http.StatusCreated,
+			wantWaits:  []time.Duration{10 * time.Second},
+		},
+		{
+			name:   "Forbidden without rate limit headers",
+			method: http.MethodPost,
+			responses: []response{
+				{status: http.StatusForbidden, header: map[string]string{"X-RateLimit-Remaining": "10"}},
+				{status: http.StatusCreated},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "throttled longer than max wait",
+			method: http.MethodGet,
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "3600"}},
+				{status: http.StatusOK},
+			},
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if b, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(b) != "payload" {
+					t.Errorf("request body = %q, want payload", b)
+				}
+				res := tt.responses[calls]
+				calls++
+				for k, v := range res.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(res.status)
+			}))
+			defer ts.Close()
+
+			var waits []time.Duration
+			tr := NewRetryTransport(nil)
+			tr.now = func() time.Time { return now }
+			tr.sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			ctx := context.Background()
+			if tt.retryable {
+				ctx = WithRetryable(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, ts.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := (&http.Client{Transport: tr}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+			if diff := cmp.Diff(tt.wantWaits, waits); diff != "" {
+				t.Errorf("waits have diff (-want +got):\n%s", diff)
+			}
+			if want := len(tt.wantWaits) + 1; calls != want {
+				t.Errorf("server received %d requests, want %d", calls, want)
+			}
+		})
+	}
+}